@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// DeviceCode is a pending device authorization, approved by the user in a browser
+type DeviceCode struct {
+	DeviceCode      string `json:"deviceCode"`
+	UserCode        string `json:"userCode"`
+	VerificationURL string `json:"verificationUrl"`
+	ExpiresIn       int    `json:"expiresIn"`
+	Interval        int    `json:"interval"`
+}
+
+// Expiry returns how long the device code remains valid, 15 minutes if the API doesn't say
+func (d DeviceCode) Expiry() time.Duration {
+	if d.ExpiresIn <= 0 {
+		return 15 * time.Minute
+	}
+
+	return time.Duration(d.ExpiresIn) * time.Second
+}
+
+// PollInterval returns how long to wait between checks for approval
+func (d DeviceCode) PollInterval() time.Duration {
+	if d.Interval <= 0 {
+		return 5 * time.Second
+	}
+
+	return time.Duration(d.Interval) * time.Second
+}
+
+// Device authorization statuses
+const (
+	DevicePending  = "pending"
+	DeviceSlowDown = "slow_down"
+	DeviceApproved = "approved"
+)
+
+// DeviceToken is the state of a device authorization, holding the API token once approved
+type DeviceToken struct {
+	Status string `json:"status"`
+	Token  string `json:"token,omitempty"`
+}
+
+// StartDeviceLogin requests a device code for the user to approve in a browser
+func (c Client) StartDeviceLogin(ctx context.Context) (DeviceCode, error) {
+	var code DeviceCode
+	err := c.post(ctx, "/auth/device", struct{}{}, &code)
+
+	return code, err
+}
+
+// PollDeviceLogin checks whether a device code has been approved
+func (c Client) PollDeviceLogin(ctx context.Context, deviceCode string) (DeviceToken, error) {
+	payload := struct {
+		DeviceCode string `json:"deviceCode"`
+	}{deviceCode}
+
+	var token DeviceToken
+	err := c.post(ctx, "/auth/device/token", payload, &token)
+
+	return token, err
+}
+
+// RevokeToken invalidates the token the client is authenticated with
+func (c Client) RevokeToken(ctx context.Context) error {
+	return c.del(ctx, "/auth/token")
+}
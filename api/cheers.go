@@ -0,0 +1,31 @@
+package api
+
+import "context"
+
+// Cheer is a request to award a feat to a developer
+type Cheer struct {
+	UserID string `json:"toUserId"`
+	FeatID string `json:"featId"`
+	Msg    string `json:"message,omitempty"`
+
+	// IdempotencyKey prevents a retried cheer from being awarded twice, generated if empty
+	IdempotencyKey string `json:"-"`
+}
+
+// CheerResult is the outcome of a cheer
+type CheerResult struct {
+	DeliveredToActiveUser bool `json:"deliveredToActiveUser"`
+	Karma                 int  `json:"karma"`
+}
+
+// CreateCheer sends a cheer
+func (c Client) CreateCheer(ctx context.Context, cheer Cheer) (CheerResult, error) {
+	if cheer.IdempotencyKey == "" {
+		cheer.IdempotencyKey = NewIdempotencyKey()
+	}
+
+	var res CheerResult
+	err := c.postIdempotent(ctx, "/cheers", cheer.IdempotencyKey, cheer, &res)
+
+	return res, err
+}
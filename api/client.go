@@ -1,20 +1,69 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
-
-	"github.com/parnurzeal/gorequest"
+	"sync/atomic"
+	"time"
 )
 
+// DefaultTimeout is the time limit for a single API call when none is configured
+const DefaultTimeout = 30 * time.Second
+
+// ErrCancelled is returned when a request is interrupted, e.g. with Ctrl-C
+var ErrCancelled = errors.New("cancelled")
+
 // Client facilitates making HTTP requests to the Karma API
 type Client struct {
-	http *gorequest.SuperAgent
-	host string
+	http     *http.Client
+	host     string
+	token    string
+	teamID   string
+	version  string
+	attempts int
+	notify   func(msg string)
+	// reached is set once a request got an answer from the API, shared by copies of the client
+	reached *int32
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithTimeout sets the time limit for each API call
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.http = &http.Client{Timeout: timeout}
+	}
+}
+
+// WithHTTPClient sets the underlying HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithRetries sets how many times a failed idempotent request is retried
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.attempts = retries + 1
+	}
+}
+
+// WithNotifier sets a function called with messages meant for the user, e.g. when waiting before a retry
+func WithNotifier(notify func(msg string)) Option {
+	return func(c *Client) {
+		c.notify = notify
+	}
 }
 
 // HTTPError is an error returned by a failed API call
@@ -34,59 +83,175 @@ func (err HTTPError) Error() string {
 }
 
 // NewClient returns an authenticated client
-func NewClient(host string, token string, teamID string, version string) Client {
-	authorization := fmt.Sprintf("token %s", token)
+func NewClient(host string, token string, teamID string, version string, opts ...Option) Client {
+	c := Client{
+		http:     &http.Client{Timeout: DefaultTimeout},
+		host:     host,
+		token:    token,
+		teamID:   teamID,
+		version:  version,
+		attempts: DefaultRetries + 1,
+		notify:   func(string) {},
+		reached:  new(int32),
+	}
 
-	return Client{
-		http: gorequest.New().
-			Set("Authorization", authorization).
-			Set("X-Team-Id", teamID).
-			Set("X-CLI-Version", version),
-		host: host,
+	for _, opt := range opts {
+		opt(&c)
 	}
-}
 
-// Post makes a POST request to server
-func (c Client) Post(endpoint string, payload interface{}, data interface{}) error {
-	resp, _, errs := c.http.Clone().
-		Post(c.host + endpoint).
-		Send(payload).
-		EndStruct(&data)
+	return c
+}
 
-	if len(errs) != 0 {
-		return errs[0]
+// With returns a copy of the client with options applied
+func (c Client) With(opts ...Option) Client {
+	for _, opt := range opts {
+		opt(&c)
 	}
 
-	success := resp.StatusCode >= 200 && resp.StatusCode < 300
+	return c
+}
 
-	if !success {
-		return HandleHTTPError(resp)
-	}
+// WithToken returns a copy of the client authenticated with another token
+func (c Client) WithToken(token string) Client {
+	c.token = token
+	return c
+}
 
-	return nil
+// Host returns the base URL of the API the client talks to
+func (c Client) Host() string {
+	return c.host
 }
 
-// Get makes a GET request to API server and assign response to data struct
-func (c Client) Get(endpoint string, data interface{}) error {
-	resp, _, errs := c.http.Clone().
-		Get(c.host + endpoint).
-		EndStruct(&data)
+// Reached tells whether a request of this client, or of a copy of it, got an answer from the API
+func (c Client) Reached() bool {
+	return c.reached != nil && atomic.LoadInt32(c.reached) == 1
+}
+
+// Version returns the CLI version sent with every request
+func (c Client) Version() string {
+	return c.version
+}
 
-	if len(errs) != 0 {
-		return errs[0]
+// do sends a request to the API and decodes the JSON response into data, if not nil.
+// GET requests and requests with an idempotency key are retried on transient failures.
+func (c Client) do(ctx context.Context, method string, endpoint string, idempotencyKey string, payload interface{}, data interface{}) error {
+	var body []byte
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = b
 	}
 
-	success := resp.StatusCode >= 200 && resp.StatusCode < 300
+	attempts := 1
+	if method == http.MethodGet || method == http.MethodDelete || idempotencyKey != "" {
+		attempts = c.attempts
+	}
+
+	var waited time.Duration
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, endpoint, idempotencyKey, body)
+		if err == nil && resp.StatusCode < 500 && c.reached != nil {
+			atomic.StoreInt32(c.reached, 1)
+		}
 
-	if !success {
-		return HandleHTTPError(resp)
+		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+
+			if waited > 0 {
+				c.notify(fmt.Sprintf("Request succeeded after waiting %s for the Karma API.", waited.Round(100*time.Millisecond)))
+			}
+
+			if data == nil || resp.StatusCode == http.StatusNoContent {
+				return nil
+			}
+
+			if err := json.NewDecoder(resp.Body).Decode(data); err != nil && err != io.EOF {
+				return fmt.Errorf("could not decode response from %s: %w", resp.Request.URL, err)
+			}
+
+			return nil
+		}
+
+		if err == nil {
+			err = HandleHTTPError(resp)
+			resp.Body.Close()
+		}
+
+		if ctx.Err() == context.Canceled {
+			return fmt.Errorf("request to %s%s %w", c.host, endpoint, ErrCancelled)
+		}
+
+		wait, retry := backoff(attempt, resp, err)
+		if !retry || attempt >= attempts {
+			if waited > 0 {
+				return fmt.Errorf("%w (gave up after %d attempts and %s of waiting)", err, attempt, waited.Round(100*time.Millisecond))
+			}
+			return err
+		}
+
+		c.notify(fmt.Sprintf("%s, retrying in %s (attempt %d of %d)...", retryReason(resp, err), wait.Round(100*time.Millisecond), attempt+1, attempts))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("request to %s%s %w", c.host, endpoint, ErrCancelled)
+		case <-time.After(wait):
+			waited += wait
+		}
+	}
+}
+
+// send makes a single HTTP request, leaving the response body to the caller
+func (c Client) send(ctx context.Context, method string, endpoint string, idempotencyKey string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
 	}
 
-	return nil
+	req, err := http.NewRequestWithContext(ctx, method, c.host+endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.token))
+	req.Header.Set("X-Team-Id", c.teamID)
+	req.Header.Set("X-CLI-Version", c.version)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	return c.http.Do(req)
+}
+
+func (c Client) get(ctx context.Context, endpoint string, data interface{}) error {
+	return c.do(ctx, http.MethodGet, endpoint, "", nil, data)
+}
+
+func (c Client) post(ctx context.Context, endpoint string, payload interface{}, data interface{}) error {
+	return c.do(ctx, http.MethodPost, endpoint, "", payload, data)
+}
+
+func (c Client) patch(ctx context.Context, endpoint string, payload interface{}, data interface{}) error {
+	return c.do(ctx, http.MethodPatch, endpoint, "", payload, data)
+}
+
+func (c Client) del(ctx context.Context, endpoint string) error {
+	return c.do(ctx, http.MethodDelete, endpoint, "", nil, nil)
+}
+
+// postIdempotent makes a POST request the server deduplicates by key, so it can be retried safely
+func (c Client) postIdempotent(ctx context.Context, endpoint string, idempotencyKey string, payload interface{}, data interface{}) error {
+	return c.do(ctx, http.MethodPost, endpoint, idempotencyKey, payload, data)
 }
 
 // HandleHTTPError catches HTTP errors and prints them out
-func HandleHTTPError(resp gorequest.Response) error {
+func HandleHTTPError(resp *http.Response) error {
 	httpError := HTTPError{
 		StatusCode: resp.StatusCode,
 		RequestURL: resp.Request.URL,
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Stats describe the level of a user
+type Stats struct {
+	Level            int   `json:"level"`
+	Multiplier       int   `json:"multiplier"`
+	Progress         int   `json:"progress"`
+	KarmaToNextLevel int64 `json:"karmaToNextLevel"`
+}
+
+// DashboardUser is the signed in user as seen on the dashboard
+type DashboardUser struct {
+	ID                string `json:"id"`
+	Stats             Stats  `json:"stats"`
+	TotalAccruedKarma int64  `json:"totalAccruedKarma"`
+}
+
+// Leaderboard holds team member names and their levels, in the same order
+type Leaderboard struct {
+	Names  []string  `json:"names"`
+	Levels []float64 `json:"levels"`
+}
+
+// LogEntry is a karma event, usually a cheer
+type LogEntry struct {
+	ID          string `json:"id,omitempty"`
+	Ago         string `json:"ago"`
+	From        string `json:"from"`
+	ToUser      string `json:"toUser"`
+	Karma       int    `json:"karma"`
+	FeatID      string `json:"featId"`
+	Message     string `json:"message"`
+	CurrentUser bool   `json:"currentUser"`
+}
+
+// Dashboard is the overview of the signed in user and their team
+type Dashboard struct {
+	User        DashboardUser `json:"user"`
+	Leaderboard Leaderboard   `json:"leaderboard"`
+	Logs        []LogEntry    `json:"logs"`
+}
+
+// GetDashboard returns the dashboard of the signed in user
+func (c Client) GetDashboard(ctx context.Context) (Dashboard, error) {
+	var dashboard Dashboard
+	err := c.get(ctx, "/dashboard", &dashboard)
+
+	return dashboard, err
+}
+
+// Leaderboard periods, each compared with the one before it
+const (
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodAll     = "all"
+)
+
+// Leaderboard rankings
+const (
+	RankByKarma       = "karma"
+	RankByLevel       = "level"
+	RankByCheersGiven = "cheers-given"
+)
+
+// RankingEntry is the standing of a team member over a period
+type RankingEntry struct {
+	UserID string `json:"userId"`
+	Name   string `json:"name"`
+	Rank   int    `json:"rank"`
+	// PreviousRank is the rank over the previous period, 0 if the member wasn't ranked
+	PreviousRank int   `json:"previousRank"`
+	Karma        int64 `json:"karma"`
+	Level        int   `json:"level"`
+	CheersGiven  int   `json:"cheersGiven"`
+}
+
+// Ranking is the leaderboard of the current team over a period, best first
+type Ranking struct {
+	Period  string         `json:"period"`
+	By      string         `json:"by"`
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	Entries []RankingEntry `json:"entries"`
+}
+
+// GetRanking returns the leaderboard of the current team over a period, ranked by karma
+// received, level or cheers given
+func (c Client) GetRanking(ctx context.Context, period string, by string) (Ranking, error) {
+	query := url.Values{"period": {period}, "by": {by}}
+
+	var ranking Ranking
+	err := c.get(ctx, "/leaderboard?"+query.Encode(), &ranking)
+
+	return ranking, err
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorKind tells why an API call failed
+type ErrorKind int
+
+const (
+	// KindUnknown is an error that does not fit any other kind, e.g. a rejected request
+	KindUnknown ErrorKind = iota
+	// KindAuth means the API token is missing, invalid or expired
+	KindAuth
+	// KindNetwork means the API could not be reached
+	KindNetwork
+	// KindServer means the API failed on its side or is rate limiting
+	KindServer
+	// KindVersion means the API no longer supports this version of the CLI
+	KindVersion
+)
+
+// Classify tells the kind of an error returned by the client
+func Classify(err error) ErrorKind {
+	if err == nil || errors.Is(err, ErrCancelled) {
+		return KindUnknown
+	}
+
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) {
+		return KindNetwork
+	}
+
+	switch code := httpErr.StatusCode; {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return KindAuth
+	case code == http.StatusUpgradeRequired || code == http.StatusGone:
+		return KindVersion
+	case code == http.StatusTooManyRequests || code >= 500:
+		return KindServer
+	default:
+		return KindUnknown
+	}
+}
+
+// Hint suggests how to fix an error of this kind
+func (k ErrorKind) Hint() string {
+	switch k {
+	case KindAuth:
+		return "Your API token was rejected, it may have expired. Run 'karma login' to sign in again."
+	case KindNetwork:
+		return "Could not reach the Karma API. Check your connection, or KARMA_HOST if you set it."
+	case KindServer:
+		return "The Karma API is having trouble. Please try again in a few minutes."
+	case KindVersion:
+		return "This version of the CLI is no longer supported. Please upgrade at https://github.com/krmdv/cli/releases."
+	default:
+		return ""
+	}
+}
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindAuth:
+		return "auth"
+	case KindNetwork:
+		return "network"
+	case KindServer:
+		return "server"
+	case KindVersion:
+		return "version"
+	default:
+		return "unknown"
+	}
+}
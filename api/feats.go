@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"net/url"
+)
+
+// Feat is something a developer can be cheered for, worth an amount of karma
+type Feat struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Slug  string `json:"slug"`
+	Karma int    `json:"karma"`
+	// Uses is how many times the feat was cheered, set by the API
+	Uses int `json:"uses,omitempty"`
+	// Archived feats can't be cheered anymore, past cheers keep their karma
+	Archived bool `json:"archived,omitempty"`
+}
+
+// FeatChanges are the fields of a feat to update, nil ones are left unchanged
+type FeatChanges struct {
+	Label *string `json:"label,omitempty"`
+	Slug  *string `json:"slug,omitempty"`
+	Karma *int    `json:"karma,omitempty"`
+	// Archived set to false restores an archived feat
+	Archived *bool `json:"archived,omitempty"`
+}
+
+// FeatUpdate changes a feat as part of a FeatPlan
+type FeatUpdate struct {
+	ID string `json:"id"`
+	FeatChanges
+}
+
+// FeatPlan is a set of changes to the feats of a team, applied all at once
+type FeatPlan struct {
+	Create  []Feat       `json:"create,omitempty"`
+	Update  []FeatUpdate `json:"update,omitempty"`
+	Archive []string     `json:"archive,omitempty"`
+}
+
+// ListFeats returns the feats of the current team
+func (c Client) ListFeats(ctx context.Context) ([]Feat, error) {
+	var feats []Feat
+	err := c.get(ctx, "/feats", &feats)
+
+	return feats, err
+}
+
+// ListAllFeats returns the feats of the current team, archived ones included
+func (c Client) ListAllFeats(ctx context.Context) ([]Feat, error) {
+	var feats []Feat
+	err := c.get(ctx, "/feats?archived=true", &feats)
+
+	return feats, err
+}
+
+// CreateFeat adds a feat to the current team, admins only
+func (c Client) CreateFeat(ctx context.Context, feat Feat) (Feat, error) {
+	payload := struct {
+		Label string `json:"label"`
+		Slug  string `json:"slug"`
+		Karma int    `json:"karma"`
+	}{feat.Label, feat.Slug, feat.Karma}
+
+	var created Feat
+	err := c.post(ctx, "/feats", payload, &created)
+
+	return created, err
+}
+
+// UpdateFeat changes some fields of a feat, admins only
+func (c Client) UpdateFeat(ctx context.Context, id string, changes FeatChanges) (Feat, error) {
+	var updated Feat
+	err := c.patch(ctx, "/feats/"+url.PathEscape(id), changes, &updated)
+
+	return updated, err
+}
+
+// ArchiveFeat stops a feat from being cheered, admins only
+func (c Client) ArchiveFeat(ctx context.Context, id string) (Feat, error) {
+	var archived Feat
+	err := c.post(ctx, "/feats/"+url.PathEscape(id)+"/archive", nil, &archived)
+
+	return archived, err
+}
+
+// ApplyFeats applies all the changes of a plan or none of them, and returns the resulting
+// feats, admins only
+func (c Client) ApplyFeats(ctx context.Context, plan FeatPlan) ([]Feat, error) {
+	var feats []Feat
+	err := c.post(ctx, "/feats/apply", plan, &feats)
+
+	return feats, err
+}
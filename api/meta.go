@@ -0,0 +1,18 @@
+package api
+
+import "context"
+
+// Meta describes the API and the CLI versions it supports
+type Meta struct {
+	APIVersion       string `json:"apiVersion"`
+	MinCLIVersion    string `json:"minCliVersion"`
+	LatestCLIVersion string `json:"latestCliVersion"`
+}
+
+// GetMeta returns the API version and the CLI versions it supports
+func (c Client) GetMeta(ctx context.Context) (Meta, error) {
+	var meta Meta
+	err := c.get(ctx, "/meta", &meta)
+
+	return meta, err
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetries is how many times a failed idempotent request is retried
+	DefaultRetries = 3
+
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 8 * time.Second
+
+	// maxRetryAfter is the longest Retry-After we are willing to wait for
+	maxRetryAfter = time.Minute
+)
+
+func init() {
+	mrand.Seed(time.Now().UnixNano())
+}
+
+// NewIdempotencyKey returns a random key identifying a request across retries
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// backoff tells whether a failed attempt is worth retrying and how long to wait before doing so
+func backoff(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if _, ok := err.(HTTPError); err != nil && !ok {
+		// connection dropped, DNS failure, timeout...
+		return jitter(attempt), true
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return 0, false
+	}
+
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+		return wait, wait <= maxRetryAfter
+	}
+
+	return jitter(attempt), true
+}
+
+// jitter returns an exponential backoff with random jitter, so clients don't retry in lockstep
+func jitter(attempt int) time.Duration {
+	d := time.Duration(float64(baseBackoff) * math.Pow(2, float64(attempt-1)))
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+
+	return d/2 + time.Duration(mrand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an HTTP date
+func retryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func retryReason(resp *http.Response, err error) string {
+	if _, ok := err.(HTTPError); !ok {
+		return fmt.Sprintf("Could not reach the Karma API (%v)", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "Rate limited by the Karma API"
+	}
+
+	return fmt.Sprintf("Karma API unavailable (HTTP %d)", resp.StatusCode)
+}
+
+// IsTemporary reports whether an error returned by the client is worth trying again later,
+// i.e. the API was unreachable, rate limiting or failing on its side
+func IsTemporary(err error) bool {
+	if err == nil || errors.Is(err, ErrCancelled) {
+		return false
+	}
+
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+
+	return true
+}
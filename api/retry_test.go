@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	future := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	if got, ok := retryAfter(future); !ok || got <= 20*time.Second || got > 30*time.Second {
+		t.Errorf("retryAfter(%q) = %v, %v, want about 30s", future, got, ok)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	response := func(status int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		name    string
+		attempt int
+		resp    *http.Response
+		err     error
+		min     time.Duration
+		max     time.Duration
+		retry   bool
+	}{
+		{"network error", 1, nil, errors.New("connection refused"), 250 * time.Millisecond, 500 * time.Millisecond, true},
+		{"not found", 1, response(404, ""), HTTPError{StatusCode: 404}, 0, 0, false},
+		{"unauthorized", 2, response(401, ""), HTTPError{StatusCode: 401}, 0, 0, false},
+		{"server error", 2, response(500, ""), HTTPError{StatusCode: 500}, 500 * time.Millisecond, time.Second, true},
+		{"unavailable with retry-after", 1, response(503, "2"), HTTPError{StatusCode: 503}, 2 * time.Second, 2 * time.Second, true},
+		{"rate limited for too long", 1, response(429, "120"), HTTPError{StatusCode: 429}, 2 * time.Minute, 2 * time.Minute, false},
+		{"capped", 10, response(502, ""), HTTPError{StatusCode: 502}, maxBackoff / 2, maxBackoff, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, retry := backoff(tt.attempt, tt.resp, tt.err)
+			if retry != tt.retry {
+				t.Errorf("retry = %v, want %v", retry, tt.retry)
+			}
+			if wait < tt.min || wait > tt.max {
+				t.Errorf("wait = %v, want between %v and %v", wait, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Team is a Karma team, mapped to a GitHub organization
+type Team struct {
+	ID    string `json:"id"`
+	Token string `json:"apiToken"`
+	Name  string `json:"name"`
+	Users []User `json:"users"`
+}
+
+// Invite is a link to join the current team with a Karma account
+type Invite struct {
+	URL       string    `json:"url"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SetTeam makes the GitHub organization the current team and returns it with its members
+func (c Client) SetTeam(ctx context.Context, githubLogin string) (Team, error) {
+	payload := struct {
+		GithubLogin string `json:"githubLogin"`
+	}{githubLogin}
+
+	var team Team
+	err := c.post(ctx, "/teams", payload, &team)
+
+	return team, err
+}
+
+// SetSlackWebhookURL sets the Slack webhook notifications are sent to for the current team
+func (c Client) SetSlackWebhookURL(ctx context.Context, webhookURL string) error {
+	payload := struct {
+		SlackWebhookURL string `json:"slackWebhookUrl"`
+	}{webhookURL}
+
+	return c.post(ctx, "/teams/current/slack-webhook-url", payload, nil)
+}
+
+// GetTeam returns the current team with its members
+func (c Client) GetTeam(ctx context.Context) (Team, error) {
+	var team Team
+	err := c.get(ctx, "/teams/current", &team)
+
+	return team, err
+}
+
+// CreateInvite returns a link to join the current team with a role, for an email if not empty
+func (c Client) CreateInvite(ctx context.Context, email string, role string) (Invite, error) {
+	payload := struct {
+		Email string `json:"email,omitempty"`
+		Role  string `json:"role"`
+	}{email, role}
+
+	var invite Invite
+	err := c.post(ctx, "/teams/current/invites", payload, &invite)
+
+	return invite, err
+}
+
+// SetMemberRole changes the role of a member of the current team, admins only
+func (c Client) SetMemberRole(ctx context.Context, userID string, role string) (User, error) {
+	payload := struct {
+		Role string `json:"role"`
+	}{role}
+
+	var user User
+	err := c.patch(ctx, "/teams/current/members/"+url.PathEscape(userID), payload, &user)
+
+	return user, err
+}
+
+// RemoveMember removes a member from the current team, admins only
+func (c Client) RemoveMember(ctx context.Context, userID string) error {
+	return c.del(ctx, "/teams/current/members/"+url.PathEscape(userID))
+}
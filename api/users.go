@@ -0,0 +1,35 @@
+package api
+
+import "context"
+
+// User is a Karma user, either signed in or a member of the current team
+type User struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Login   string   `json:"login,omitempty"`
+	Email   string   `json:"email,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	// Active tells whether the user has a Karma account, so cheers reach them
+	Active bool   `json:"active"`
+	Role   string `json:"role,omitempty"`
+}
+
+const (
+	// RoleAdmin lets a team member manage feats and members
+	RoleAdmin = "admin"
+	// RoleMember lets a team member cheer and be cheered
+	RoleMember = "member"
+)
+
+// GetMe returns the user the client is authenticated as
+func (c Client) GetMe(ctx context.Context) (User, error) {
+	var user User
+	err := c.get(ctx, "/users/me", &user)
+
+	return user, err
+}
+
+// CompleteSetup marks the onboarding of the current user as done
+func (c Client) CompleteSetup(ctx context.Context) error {
+	return c.post(ctx, "/users/me/setup", struct{}{}, nil)
+}
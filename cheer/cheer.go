@@ -17,44 +17,53 @@ limitations under the License.
 package cheer
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/MakeNowJust/heredoc"
 	"github.com/fatih/color"
 	"github.com/krmdv/cli/api"
 	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/krmdv/cli/queue"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
-// Users is
-type Users []struct {
-	Name string `mapstructure:"name"`
-	ID   string `mapstructure:"id"`
+// cheerOutput is the structured result of a cheer
+type cheerOutput struct {
+	To      string `json:"to"`
+	Feat    string `json:"feat"`
+	Message string `json:"message,omitempty"`
+	Queued  bool   `json:"queued"`
+	Error   string `json:"error,omitempty"`
+	api.CheerResult
 }
 
-// Feats is
-type Feats []struct {
-	ID    string `mapstructure:"id"`
-	Label string `mapstructure:"label"`
-	Slug  string `mapstructure:"slug"`
-	Karma int    `mapstructure:"karma"`
-}
+// maxParallelCheers bounds how many cheers are sent at once when cheering several developers
+const maxParallelCheers = 4
 
 // NewCmdCheer creates a cheer command
-func NewCmdCheer(client api.Client, conf config.Configuration) *cobra.Command {
+func NewCmdCheer(client *api.Client, conf *config.Configuration) *cobra.Command {
 
 	var cmd = &cobra.Command{
-		Use:   "cheer <developer>",
+		Use:   "cheer [<developer>...]",
 		Short: "Cheer a dev",
 		Long: heredoc.Doc(`
-			Cheer a developer for a given feat.
+			Cheer one or more developers for a given feat.
 
 			The available developers and feats are those configured for your current team.
+			Developers can be given by name, GitHub login, email or alias, and feats by
+			slug or label, ignoring case and punctuation. A unique prefix is enough.
 			
+			Several developers get one cheer each, sent in parallel. A summary tells how
+			each went, and the command fails if any of them did. With --output json, the
+			result is then a list.
+
 			Note that you cannot cheer yourself.
 		`),
 		Example: heredoc.Doc(`
@@ -64,109 +73,128 @@ func NewCmdCheer(client api.Client, conf config.Configuration) *cobra.Command {
 			# cheer John Doe and pick feat manually
 			$ karma c johndoe
 
+			# names and slugs are matched loosely
+			$ karma c JohnDoe -f React
+
+			# cheer everyone after a mob session, one cheer each
+			$ karma c alice bob carol -f pairing -m "Great session"
+
 			# cheer Dab Abramov for being a React Guru
 			$ karma c gaearon -f react -msg "Well done, Dan!"
 
 			# cheer Troy Hunt for being a Super Hacker
 			$ karma c troyhunt -f hacker -msg "Nothing like DDoS for breakfast!"
 		`),
-		Aliases: []string{"c"},
+		Aliases:           []string{"c"},
+		ValidArgsFunction: CompleteDevelopers,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			config.Get()
-
 			if err := config.CheckLoaded(); err != nil {
 				return err
 			}
 
-			return cheerRun(client, conf, args, cmd.Flags())
+			return output.HandleInterrupt(cheerRun(cmd.Context(), *client, *conf, args, cmd.Flags()))
 		},
 	}
 
 	cmd.SilenceUsage = true
-	cmd.Flags().StringP("feat", "f", "", "The slug of the feat to cheer the dev for")
-	cmd.Flags().StringP("msg", "m", "", "An optional message for this dev")
+	addFlags(cmd.Flags())
+	cmd.RegisterFlagCompletionFunc("feat", completeFeats)
 
 	return cmd
 }
 
-func cheerRun(client api.Client, conf config.Configuration, args []string, flags *pflag.FlagSet) error {
-	len := len(args)
-
-	user := ""
-	feat, _ := flags.GetString("feat")
-	msg, _ := flags.GetString("msg")
+// Interactive runs the cheer flow for a developer, prompting for the feat and message.
+// It returns terminal.InterruptErr if the user hits Ctrl-C.
+func Interactive(ctx context.Context, client api.Client, conf config.Configuration, user string) error {
+	flags := pflag.NewFlagSet("cheer", pflag.ContinueOnError)
+	addFlags(flags)
+	output.AddFlag(flags)
 
-	if len >= 1 {
-		user = args[0]
-	}
+	return cheerRun(ctx, client, conf, []string{user}, flags)
+}
 
-	askForMsg := feat == "" && msg == ""
+func addFlags(flags *pflag.FlagSet) {
+	flags.StringP("feat", "f", "", "The slug of the feat to cheer the dev for")
+	flags.StringP("msg", "m", "", "An optional message for this dev")
+	flags.Bool("offline", false, "Queue the cheer to be sent later instead of sending it now")
+}
 
-	// Find user from argument or from prompt
-	userID := ""
-	var users []string
-	for _, m := range conf.Users {
-		if m.Name == user {
-			userID = m.ID
-			break
-		} else {
-			users = append(users, m.Name)
-		}
+func cheerRun(ctx context.Context, client api.Client, conf config.Configuration, args []string, flags *pflag.FlagSet) error {
+	format, err := output.FromFlags(flags)
+	if err != nil {
+		return err
 	}
 
-	if userID == "" {
-		err := survey.AskOne(&survey.Select{
-			Message: "Who do you want to cheer?",
-			Options: users,
-		}, &user, survey.WithValidator(survey.Required))
+	feat, _ := flags.GetString("feat")
+	msg, _ := flags.GetString("msg")
+	offline, _ := flags.GetBool("offline")
+
+	askForMsg := feat == "" && msg == ""
 
-		if err == terminal.InterruptErr {
-			fmt.Println("interrupted")
+	interactive := isatty.IsTerminal(os.Stdin.Fd())
 
-			os.Exit(0)
-		} else if err != nil {
-			panic(err)
+	// Names or slugs missing from the cache may have been added since it was saved
+	if !offline {
+		missing := false
+		for _, arg := range args {
+			_, err := MatchUser(conf.Users, arg)
+			missing = missing || IsNoMatch(err)
+		}
+		if _, err := MatchFeat(conf.Feats, feat); feat != "" && IsNoMatch(err) {
+			missing = true
 		}
 
-		for _, d := range conf.Users {
-			if d.Name == user && d.ID != "" {
-				userID = d.ID
-				break
-			}
+		if missing {
+			refreshTeam(ctx, client, &conf)
 		}
 	}
 
+	// Find users from arguments or from prompt
+	recipients, err := pickRecipients(conf.Users, args, interactive)
+	if err != nil {
+		return err
+	}
+
 	// Find feat from argument or from prompt
 	featID := ""
-	var feats []string
-	for _, f := range conf.Feats {
-		if f.Karma > 0 {
-			if f.Slug == feat {
-				featID = f.ID
-				break
-			} else {
-				feats = append(feats, f.Label)
+	if feat != "" {
+		f, err := MatchFeat(conf.Feats, feat)
+		if err != nil {
+			suggestion, err := didYouMean(err, interactive)
+			if err != nil {
+				return err
 			}
+			f, _ = MatchFeat(conf.Feats, suggestion)
 		}
+		featID, feat = f.ID, f.Slug
 	}
 
 	if featID == "" {
+		var feats []string
+		for _, f := range conf.Feats {
+			if f.Karma > 0 {
+				feats = append(feats, f.Label)
+			}
+		}
+
+		message := "What to cheer that dev for?"
+		if len(recipients) > 1 {
+			message = "What to cheer these devs for?"
+		}
+
 		err := survey.AskOne(&survey.Select{
-			Message: "What to cheer that dev for?",
+			Message: message,
 			Options: feats,
 		}, &feat, survey.WithValidator(survey.Required))
 
-		if err == terminal.InterruptErr {
-			fmt.Println("interrupted")
-
-			os.Exit(0)
-		} else if err != nil {
-			panic(err)
+		if err != nil {
+			return err
 		}
 
+		// the prompt shows labels, results name feats by slug like the flag
 		for _, f := range conf.Feats {
 			if f.Label == feat {
-				featID = f.ID
+				featID, feat = f.ID, f.Slug
 				break
 			}
 		}
@@ -178,39 +206,274 @@ func cheerRun(client api.Client, conf config.Configuration, args []string, flags
 			Message: "Any comment?",
 		}, &msg, survey.WithValidator(survey.MaxLength(42)))
 
-		if err == terminal.InterruptErr {
-			fmt.Println("interrupted")
-
-			os.Exit(0)
-		} else if err != nil {
-			panic(err)
+		if err != nil {
+			return err
 		}
 	}
 
-	type cheerPayload struct {
-		UserID string `json:"toUserId"`
-		FeatID string `json:"featId"`
+	if len(recipients) > 1 {
+		return cheerMany(ctx, client, format, recipients, featID, feat, msg, offline)
 	}
 
-	type cheerRes struct {
-		DeliveredToActiveUser bool `json:"deliveredToActiveUser"`
-		Karma                 int  `json:"karma"`
+	user := recipients[0].Name
+
+	cheer := api.Cheer{
+		UserID:         recipients[0].ID,
+		FeatID:         featID,
+		Msg:            msg,
+		IdempotencyKey: api.NewIdempotencyKey(),
 	}
 
-	var res cheerRes
+	out := cheerOutput{To: user, Feat: feat, Message: msg}
+
+	if offline {
+		return queueCheer(format, out, cheer, nil)
+	}
 
-	if err := client.Post("/cheers", cheerPayload{
-		UserID: userID,
-		FeatID: featID,
-	}, &res); err != nil {
+	res, err := client.CreateCheer(ctx, cheer)
+	if api.IsTemporary(err) {
+		return queueCheer(format, out, cheer, err)
+	} else if err != nil {
 		return err
 	}
 
+	if format.Structured() {
+		out.CheerResult = res
+		return output.Print(os.Stdout, format, out)
+	}
+
 	if !res.DeliveredToActiveUser {
-		color.Yellow(fmt.Sprintf("Uh-oh 🤭: %s has received your cheer but has no active Karma account yet - consider inviting that dev to spread the love 💌 .", user))
+		color.Yellow(fmt.Sprintf("Uh-oh 🤭: %s has received your cheer but has no active Karma account yet - consider inviting that dev with 'karma team invite %s' to spread the love 💌 .", user, inviteArg(recipients[0])))
 	}
 
 	color.Green(fmt.Sprintf("You rock, thanks for spreading good karma! %s got %v points thanks to your cheer.", user, res.Karma))
 
 	return nil
 }
+
+// pickRecipients finds the team members designated by arguments, or prompts for them when none
+// are given or some can't be found
+func pickRecipients(users []api.User, args []string, interactive bool) ([]api.User, error) {
+	var recipients []api.User
+	seen := map[string]bool{}
+	resolved := true
+
+	for _, arg := range args {
+		u, err := MatchUser(users, arg)
+		if err != nil {
+			suggestion, err := didYouMean(err, interactive)
+			if err != nil {
+				return nil, err
+			}
+			if suggestion == "" {
+				resolved = false
+				continue
+			}
+			u, _ = MatchUser(users, suggestion)
+		}
+
+		if !seen[u.ID] {
+			seen[u.ID] = true
+			recipients = append(recipients, u)
+		}
+	}
+
+	if resolved && len(recipients) > 0 {
+		return recipients, nil
+	}
+
+	var names, picked []string
+	for _, u := range users {
+		names = append(names, u.Name)
+	}
+	for _, u := range recipients {
+		picked = append(picked, u.Name)
+	}
+
+	err := survey.AskOne(&survey.MultiSelect{
+		Message: "Who do you want to cheer?",
+		Options: names,
+		Default: picked,
+	}, &picked, survey.WithValidator(survey.Required))
+
+	if err != nil {
+		return nil, err
+	}
+
+	recipients = nil
+	for _, name := range picked {
+		for _, u := range users {
+			if u.Name == name && u.ID != "" {
+				recipients = append(recipients, u)
+				break
+			}
+		}
+	}
+
+	return recipients, nil
+}
+
+// cheerMany sends the same cheer to several developers at once, queueing those that can't be sent
+// yet, and reports how each went
+func cheerMany(ctx context.Context, client api.Client, format output.Format, recipients []api.User, featID string, feat string, msg string, offline bool) error {
+	cheers := make([]api.Cheer, len(recipients))
+	outs := make([]cheerOutput, len(recipients))
+	errs := make([]error, len(recipients))
+
+	sem := make(chan struct{}, maxParallelCheers)
+	var wg sync.WaitGroup
+
+	for i, u := range recipients {
+		cheers[i] = api.Cheer{UserID: u.ID, FeatID: featID, Msg: msg, IdempotencyKey: api.NewIdempotencyKey()}
+		outs[i] = cheerOutput{To: u.Name, Feat: feat, Message: msg}
+
+		if offline {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			outs[i].CheerResult, errs[i] = client.CreateCheer(ctx, cheers[i])
+		}(i)
+	}
+
+	wg.Wait()
+
+	// the queue file is saved once per cheer, so queueing happens after all sends are done
+	failed, queued := 0, 0
+	for i := range outs {
+		switch {
+		case offline || api.IsTemporary(errs[i]):
+			if _, err := queue.Enqueue(cheers[i], outs[i].To, feat, errs[i]); err != nil {
+				outs[i].Error = err.Error()
+				failed++
+			} else {
+				outs[i].Queued = true
+				queued++
+			}
+		case errs[i] != nil:
+			outs[i].Error = errs[i].Error()
+			failed++
+		}
+	}
+
+	if format.Structured() {
+		if err := output.Print(os.Stdout, format, outs); err != nil {
+			return err
+		}
+	} else {
+		for _, out := range outs {
+			switch {
+			case out.Error != "":
+				fmt.Printf("❌ %s: %s\n", out.To, out.Error)
+			case out.Queued:
+				fmt.Printf("📮 %s: queued\n", out.To)
+			case !out.DeliveredToActiveUser:
+				fmt.Printf("✅ %s got %v points, but has no active Karma account yet 🤭\n", out.To, out.Karma)
+			default:
+				fmt.Printf("✅ %s got %v points\n", out.To, out.Karma)
+			}
+		}
+
+		for _, out := range outs {
+			if out.Error == "" && !out.Queued && !out.DeliveredToActiveUser {
+				color.Yellow("💌 Invite devs without an account to spread the love, with 'karma team invite <developer>'.")
+				break
+			}
+		}
+		if queued > 0 {
+			color.Yellow("📮 Queued cheers will be sent after your next successful command, or with 'karma queue flush'.")
+		}
+		if sent := len(outs) - failed - queued; sent > 0 {
+			color.Green(fmt.Sprintf("You rock, thanks for spreading good karma! %d of %d cheers sent.", sent, len(outs)))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d cheers failed", failed, len(outs))
+	}
+
+	return nil
+}
+
+// didYouMean handles an argument matching nothing or several candidates. When prompts can be
+// shown, it offers the closest candidate, returning it if accepted, or "" to prompt for another
+// one. Otherwise the error is returned as is.
+func didYouMean(err error, interactive bool) (string, error) {
+	noMatch, ok := err.(*noMatchError)
+	if !ok || !interactive {
+		return "", err
+	}
+
+	if len(noMatch.suggestions) != 1 {
+		color.Yellow(fmt.Sprintf("Hmm, %s.", err))
+		return "", nil
+	}
+
+	var yes bool
+	err = survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("No %s matching %q, did you mean %s?", noMatch.what, noMatch.query, noMatch.suggestions[0]),
+		Default: true,
+	}, &yes)
+
+	if err != nil || !yes {
+		return "", err
+	}
+
+	return noMatch.suggestions[0], nil
+}
+
+// inviteArg designates a developer for 'karma team invite', by GitHub login when known
+func inviteArg(u api.User) string {
+	if u.Login != "" {
+		return u.Login
+	}
+
+	return fmt.Sprintf("%q", u.Name)
+}
+
+// IsNoMatch tells whether a match failed because nothing matched, as opposed to several things
+func IsNoMatch(err error) bool {
+	_, ok := err.(*noMatchError)
+	return ok
+}
+
+// refreshTeam replaces the cached team members and feats with the API's, keeping the cache
+// if the API can't be reached
+func refreshTeam(ctx context.Context, client api.Client, conf *config.Configuration) {
+	cache, err := config.FetchTeamCache(ctx, client)
+	if err != nil {
+		return
+	}
+
+	conf.Users = cache.Team.Users
+	conf.Feats = cache.Feats
+
+	if err := config.SaveTeamCache(cache); err != nil {
+		color.New(color.FgYellow).Fprintln(os.Stderr, fmt.Sprintf("Heads up! Couldn't save the team members and feats: %v", err))
+	}
+}
+
+func queueCheer(format output.Format, out cheerOutput, cheer api.Cheer, cause error) error {
+	if _, err := queue.Enqueue(cheer, out.To, out.Feat, cause); err != nil {
+		return err
+	}
+
+	notice := color.New(color.FgYellow)
+	if cause != nil {
+		notice.Fprintln(os.Stderr, fmt.Sprintf("Could not reach the Karma API (%v).", cause))
+	}
+	notice.Fprintln(os.Stderr, fmt.Sprintf("📮 Your cheer to %s is queued and will be sent after your next successful command, or with 'karma queue flush'.", out.To))
+
+	if format.Structured() {
+		out.Queued = true
+		return output.Print(os.Stdout, format, out)
+	}
+
+	return nil
+}
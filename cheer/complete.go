@@ -0,0 +1,68 @@
+package cheer
+
+import (
+	"fmt"
+
+	"github.com/krmdv/cli/config"
+	"github.com/spf13/cobra"
+)
+
+// CachedConfig reads the active profile for completions, which run without the root command
+// hooks loading it. Secrets are left alone so completing stays instant.
+func CachedConfig(cmd *cobra.Command) (config.Configuration, bool) {
+	if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+		config.SetProfile(profile)
+	}
+
+	conf, err := config.ReadProfile(config.Profile())
+
+	return conf, err == nil
+}
+
+// CompleteDevelopers suggests cached team members, by GitHub login when known as names may
+// have spaces
+func CompleteDevelopers(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	conf, ok := CachedConfig(cmd)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	given := map[string]bool{}
+	for _, arg := range args {
+		if u, err := MatchUser(conf.Users, arg); err == nil {
+			given[u.ID] = true
+		}
+	}
+
+	var completions []string
+	for _, u := range conf.Users {
+		if u.ID == conf.User.ID || given[u.ID] {
+			continue
+		}
+
+		if u.Login != "" {
+			completions = append(completions, fmt.Sprintf("%s\t%s", u.Login, u.Name))
+		} else {
+			completions = append(completions, u.Name)
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFeats suggests the slugs of cached feats awarding karma
+func completeFeats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	conf, ok := CachedConfig(cmd)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, f := range conf.Feats {
+		if f.Karma > 0 {
+			completions = append(completions, fmt.Sprintf("%s\t%s (%d pts)", f.Slug, f.Label, f.Karma))
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
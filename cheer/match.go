@@ -0,0 +1,221 @@
+package cheer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/krmdv/cli/api"
+)
+
+// candidate is something a cheer argument can designate, known by several keys
+type candidate struct {
+	name string
+	keys []string
+}
+
+// noMatchError is returned when an argument designates nothing, with close candidates if any
+type noMatchError struct {
+	what        string
+	query       string
+	suggestions []string
+}
+
+func (e *noMatchError) Error() string {
+	if len(e.suggestions) == 0 {
+		return fmt.Sprintf("no %s matching %q", e.what, e.query)
+	}
+
+	return fmt.Sprintf("no %s matching %q, did you mean %s?", e.what, e.query, orList(e.suggestions))
+}
+
+// ambiguousError is returned when an argument designates several candidates equally well
+type ambiguousError struct {
+	what    string
+	query   string
+	matches []string
+}
+
+func (e *ambiguousError) Error() string {
+	return fmt.Sprintf("%q matches several %ss: %s, please be more specific", e.query, e.what, strings.Join(e.matches, ", "))
+}
+
+func userCandidates(users []api.User) []candidate {
+	var candidates []candidate
+	for _, u := range users {
+		keys := append([]string{u.Name, u.Login, u.Email}, u.Aliases...)
+		if i := strings.Index(u.Email, "@"); i > 0 {
+			keys = append(keys, u.Email[:i])
+		}
+
+		candidates = append(candidates, candidate{name: u.Name, keys: keys})
+	}
+
+	return candidates
+}
+
+func featCandidates(feats []api.Feat) []candidate {
+	var candidates []candidate
+	for _, f := range feats {
+		candidates = append(candidates, candidate{name: f.Label, keys: []string{f.Slug, f.Label}})
+	}
+
+	return candidates
+}
+
+// MatchUser finds the team member designated by a name, GitHub login, email or alias
+func MatchUser(users []api.User, query string) (api.User, error) {
+	i, err := match("developer", userCandidates(users), query)
+	if err != nil {
+		return api.User{}, err
+	}
+
+	return users[i], nil
+}
+
+// MatchFeat finds the feat designated by a slug or label, among those awarding karma
+func MatchFeat(feats []api.Feat, query string) (api.Feat, error) {
+	var cheerable []api.Feat
+	for _, f := range feats {
+		if f.Karma > 0 {
+			cheerable = append(cheerable, f)
+		}
+	}
+
+	return FindFeat(cheerable, query)
+}
+
+// FindFeat finds the feat designated by a slug or label, whatever its karma
+func FindFeat(feats []api.Feat, query string) (api.Feat, error) {
+	i, err := match("feat", featCandidates(feats), query)
+	if err != nil {
+		return api.Feat{}, err
+	}
+
+	return feats[i], nil
+}
+
+// match returns the index of the candidate designated by query. Keys are compared ignoring case
+// and punctuation, looking for an exact match first, then a prefix, then a substring. Only the
+// first stage with matches counts, and it must have a single one.
+func match(what string, candidates []candidate, query string) (int, error) {
+	q := normalize(query)
+	if q == "" {
+		return -1, &noMatchError{what: what, query: query}
+	}
+
+	stages := []func(key string) bool{
+		func(key string) bool { return key == q },
+		func(key string) bool { return strings.HasPrefix(key, q) },
+		func(key string) bool { return strings.Contains(key, q) },
+	}
+
+	for _, matches := range stages {
+		var found []int
+		for i, c := range candidates {
+			for _, key := range c.keys {
+				if k := normalize(key); k != "" && matches(k) {
+					found = append(found, i)
+					break
+				}
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		default:
+			var names []string
+			for _, i := range found {
+				names = append(names, candidates[i].name)
+			}
+			return -1, &ambiguousError{what: what, query: query, matches: names}
+		}
+	}
+
+	return -1, &noMatchError{what: what, query: query, suggestions: suggest(candidates, q)}
+}
+
+// suggest returns the candidates with a key within a few typos of the query
+func suggest(candidates []candidate, q string) []string {
+	best := len(q)/3 + 1
+	var names []string
+
+	for _, c := range candidates {
+		d := best + 1
+		for _, key := range c.keys {
+			if k := normalize(key); k != "" {
+				if kd := distance(k, q); kd < d {
+					d = kd
+				}
+			}
+		}
+
+		switch {
+		case d < best:
+			best, names = d, []string{c.name}
+		case d == best:
+			names = append(names, c.name)
+		}
+	}
+
+	return names
+}
+
+// normalize lowercases a key and drops what people tend to type differently: spaces,
+// dashes, underscores, dots and a leading @
+func normalize(s string) string {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "@")
+
+	return strings.NewReplacer(" ", "", "-", "", "_", "", ".", "").Replace(s)
+}
+
+// distance is the Levenshtein distance between two strings
+func distance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minOf(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func minOf(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+func orList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
@@ -0,0 +1,102 @@
+package cheer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/krmdv/cli/api"
+)
+
+var users = []api.User{
+	{ID: "u2", Name: "Alice Martin", Login: "alice", Email: "alice.martin@example.com"},
+	{ID: "u3", Name: "Bob Smith", Login: "bobsmith", Email: "bob@example.com", Aliases: []string{"bobby"}},
+	{ID: "u4", Name: "Carol Jones", Login: "cjones", Email: "carol@example.com"},
+	{ID: "u5", Name: "Carl Jonas", Login: "cjonas"},
+}
+
+func TestMatchUser(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		// suggestions or matches expected in the error, when there's no single match
+		others []string
+	}{
+		{query: "alice", want: "u2"},
+		{query: "@Alice", want: "u2"},
+		{query: "alice martin", want: "u2"},
+		{query: "alicemartin", want: "u2"},
+		{query: "alice.martin", want: "u2"},
+		{query: "bob@example.com", want: "u3"},
+		{query: "bobby", want: "u3"},
+		{query: "bob", want: "u3"},
+		{query: "smith", want: "u3"},
+		{query: "cjones", want: "u4"},
+		{query: "cjon", others: []string{"Carol Jones", "Carl Jonas"}},
+		{query: "alcie", others: []string{"Alice Martin"}},
+		{query: "zed"},
+		{query: " "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := MatchUser(users, tt.query)
+			if tt.want != "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.ID != tt.want {
+					t.Errorf("MatchUser(%q) = %s, want %s", tt.query, got.ID, tt.want)
+				}
+				return
+			}
+
+			var ambiguous *ambiguousError
+			var noMatch *noMatchError
+			switch {
+			case errors.As(err, &ambiguous):
+				if !reflect.DeepEqual(ambiguous.matches, tt.others) {
+					t.Errorf("MatchUser(%q) matches %v, want %v", tt.query, ambiguous.matches, tt.others)
+				}
+			case errors.As(err, &noMatch):
+				if !reflect.DeepEqual(noMatch.suggestions, tt.others) {
+					t.Errorf("MatchUser(%q) suggests %v, want %v", tt.query, noMatch.suggestions, tt.others)
+				}
+			default:
+				t.Errorf("MatchUser(%q) = %+v, %v, want an error", tt.query, got, err)
+			}
+		})
+	}
+}
+
+func TestMatchFeat(t *testing.T) {
+	feats := []api.Feat{
+		{ID: "f1", Slug: "pairing", Label: "Pairing session", Karma: 50},
+		{ID: "f2", Slug: "review", Label: "Code review", Karma: 30},
+		{ID: "f3", Slug: "broken-build", Label: "Broke the build", Karma: -20},
+	}
+
+	tests := []struct {
+		query string
+		match string
+		find  string
+	}{
+		{query: "pairing", match: "f1", find: "f1"},
+		{query: "Code Review", match: "f2", find: "f2"},
+		{query: "pair", match: "f1", find: "f1"},
+		{query: "broken-build", find: "f3"},
+		{query: "brokenbuild", find: "f3"},
+		{query: "deploy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got, err := MatchFeat(feats, tt.query); got.ID != tt.match || (err == nil) != (tt.match != "") {
+				t.Errorf("MatchFeat(%q) = %s, %v, want %q", tt.query, got.ID, err, tt.match)
+			}
+			if got, err := FindFeat(feats, tt.query); got.ID != tt.find || (err == nil) != (tt.find != "") {
+				t.Errorf("FindFeat(%q) = %s, %v, want %q", tt.query, got.ID, err, tt.find)
+			}
+		})
+	}
+}
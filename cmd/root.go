@@ -17,14 +17,32 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/krmdv/cli/api"
 	cheerCmd "github.com/krmdv/cli/cheer"
+	completionCmd "github.com/krmdv/cli/completion"
 	"github.com/krmdv/cli/config"
+	devServerCmd "github.com/krmdv/cli/devserver"
+	featsCmd "github.com/krmdv/cli/feats"
+	hooksCmd "github.com/krmdv/cli/hooks"
+	leaderboardCmd "github.com/krmdv/cli/leaderboard"
 	loginCmd "github.com/krmdv/cli/login"
+	logoutCmd "github.com/krmdv/cli/logout"
 	meCmd "github.com/krmdv/cli/me"
+	"github.com/krmdv/cli/output"
+	profileCmd "github.com/krmdv/cli/profile"
+	queueCmd "github.com/krmdv/cli/queue"
 	setupCmd "github.com/krmdv/cli/setup"
+	statusCmd "github.com/krmdv/cli/status"
+	teamCmd "github.com/krmdv/cli/team"
+	syncCmd "github.com/krmdv/cli/teamsync"
 )
 
 var version = "v0.0.1"
@@ -38,19 +56,128 @@ var rootCmd = &cobra.Command{
 
 // Execute executes the root command.
 func Execute() {
-	rootCmd.Execute()
+	// Ctrl-C cancels in-flight API calls; a second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
+		os.Exit(1)
+	}
 }
 
 func init() {
-	conf := config.Get()
-	client := api.NewClient(config.Host(), conf.Token, conf.Team.ID, version)
+	// Configuration and client depend on the active profile, so they are loaded once flags are parsed
+	conf := &config.Configuration{}
+	client := &api.Client{}
+
+	// saveRefresh saves team members and feats refreshed in the background, if they were stale
+	var saveRefresh func()
 
 	go checkVersion()
 
+	rootCmd.PersistentFlags().String("profile", "", "configuration profile to use, instead of KARMA_PROFILE or 'karma profile use'")
+	output.AddFlag(rootCmd.PersistentFlags())
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// flags and args are valid at this point, later errors don't call for the usage
+		cmd.SilenceUsage = true
+
+		// completions read the cached profile themselves, without secrets or API calls
+		if isCompletionRequest(cmd) {
+			return nil
+		}
+
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			config.SetProfile(profile)
+		}
+
+		profile := config.Profile()
+		if err := config.ValidateProfileName(profile); err != nil {
+			return err
+		}
+
+		if profile != config.DefaultProfile && !config.ProfileExists(profile) && !isProfileCmd(cmd) {
+			return fmt.Errorf("no profile named %q, create it with 'karma profile add %s'", profile, profile)
+		}
+
+		*conf = config.Get()
+		*client = api.NewClient(config.Host(), conf.Token, conf.Team.ID, version,
+			api.WithTimeout(config.Timeout()),
+			api.WithRetries(config.Retries()),
+			api.WithNotifier(func(msg string) {
+				color.New(color.FgYellow).Fprintln(os.Stderr, msg)
+			}),
+		)
+
+		if usesTeamCache(cmd) && config.CheckLoaded() == nil && config.CacheStale() {
+			saveRefresh = config.RefreshInBackground(cmd.Context(), *client)
+		}
+
+		return nil
+	}
+
+	// Commands reaching this point succeeded, queued cheers are sent if the API answered them
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		if saveRefresh != nil {
+			saveRefresh()
+		}
+
+		if !flushesQueue(cmd) || !client.Reached() || config.CheckLoaded() != nil {
+			return
+		}
+
+		// the API answered moments ago, don't keep the command waiting if it stopped
+		queueCmd.AutoFlush(cmd.Context(), client.With(api.WithRetries(0), api.WithNotifier(func(string) {})))
+	}
+
 	rootCmd.AddCommand(cheerCmd.NewCmdCheer(client, conf))
 	rootCmd.AddCommand(meCmd.NewCmdMe(client, conf))
-	rootCmd.AddCommand(loginCmd.NewCmdLogin())
+	rootCmd.AddCommand(leaderboardCmd.NewCmdLeaderboard(client, conf))
+	rootCmd.AddCommand(loginCmd.NewCmdLogin(client))
+	rootCmd.AddCommand(logoutCmd.NewCmdLogout(client, conf))
 	rootCmd.AddCommand(setupCmd.NewCmdSetup(client))
+	rootCmd.AddCommand(queueCmd.NewCmdQueue(client))
+	rootCmd.AddCommand(statusCmd.NewCmdStatus(client, conf))
+	rootCmd.AddCommand(syncCmd.NewCmdSync(client, conf))
+	rootCmd.AddCommand(profileCmd.NewCmdProfile())
+	rootCmd.AddCommand(teamCmd.NewCmdTeam(client, conf))
+	rootCmd.AddCommand(featsCmd.NewCmdFeats(client, conf))
+	rootCmd.AddCommand(hooksCmd.NewCmdHooks(client, conf))
+	rootCmd.AddCommand(completionCmd.NewCmdCompletion())
+	rootCmd.AddCommand(devServerCmd.NewCmdDevServer())
+}
+
+// usesTeamCache tells whether a command reads cached team members and feats, which are then
+// refreshed in the background when stale
+func usesTeamCache(cmd *cobra.Command) bool {
+	return cmd.Name() == "cheer" || cmd.Name() == "me"
+}
+
+// flushesQueue tells whether queued cheers are sent after a command, which is not the case for
+// shell completions, commands managing the queue, and those not talking to the API
+func flushesQueue(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case "completion", "queue", "dev-server":
+			return false
+		}
+	}
+
+	return !isCompletionRequest(cmd)
+}
+
+// isCompletionRequest tells whether a command is the hidden one shells call to complete arguments
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
+
+func isProfileCmd(cmd *cobra.Command) bool {
+	return cmd.Name() == "profile" || cmd.HasParent() && cmd.Parent().Name() == "profile"
 }
 
 func checkVersion() {
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/devserver"
+	"github.com/krmdv/cli/queue"
+	"github.com/mitchellh/go-homedir"
+)
+
+// setenv sets environment variables for the duration of a test
+func setenv(t *testing.T, vars map[string]string) {
+	for name, value := range vars {
+		previous, set := os.LookupEnv(name)
+		if value == "" {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, value)
+		}
+
+		name := name
+		t.Cleanup(func() {
+			if set {
+				os.Setenv(name, previous)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+}
+
+func run(t *testing.T, args ...string) {
+	t.Helper()
+
+	rootCmd.SetArgs(args)
+	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("karma %v: %v", args, err)
+	}
+}
+
+// capture runs a command and returns what it printed on stdout
+func capture(t *testing.T, args ...string) []byte {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	printed := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		printed <- b
+	}()
+
+	run(t, args...)
+	w.Close()
+
+	return <-printed
+}
+
+// TestFlow logs in, cheers online and offline, flushes the queue, shows the dashboard and applies a feats file against
+// the dev server, checking what the API ends up with
+func TestFlow(t *testing.T) {
+	home := t.TempDir()
+
+	store, err := devserver.OpenStore(filepath.Join(home, "fixtures.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(devserver.NewServer(store))
+	defer srv.Close()
+
+	homedir.DisableCache = true
+	setenv(t, map[string]string{
+		"HOME":                        home,
+		"KARMA_HOST":                  srv.URL,
+		"KARMA_CREDENTIAL_STORE":      "file",
+		"KARMA_CREDENTIAL_PASSPHRASE": "test",
+		"KARMA_RETRIES":               "0",
+		"KARMA_TOKEN":                 "",
+		"KARMA_PROFILE":               "",
+	})
+
+	ctx := context.Background()
+
+	run(t, "login", "dev-token")
+
+	conf, err := config.ReadProfile(config.DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.User.ID != "u1" {
+		t.Errorf("logged in as %q, want u1", conf.User.ID)
+	}
+
+	saved, err := ioutil.ReadFile(config.ProfilePath(config.DefaultProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(saved), "dev-token") {
+		t.Errorf("the token was saved in the configuration file:\n%s", saved)
+	}
+
+	run(t, "config", "--org", "karma-dev")
+	conf, _ = config.ReadProfile(config.DefaultProfile)
+	client := api.NewClient(srv.URL, "dev-token", conf.Team.ID, "test")
+
+	run(t, "cheer", "alice", "--feat", "pairing", "--msg", "great pairing")
+	if !cheered(t, ctx, client, "Alice Martin", "great pairing") {
+		t.Error("alice's cheer is not on the dashboard")
+	}
+
+	run(t, "cheer", "bobby", "--feat", "review", "--msg", "queued review", "--offline")
+	if n := queued(t); n != 1 {
+		t.Fatalf("%d cheers queued, want 1", n)
+	}
+	if cheered(t, ctx, client, "Bob Smith", "queued review") {
+		t.Error("an offline cheer was sent")
+	}
+
+	run(t, "queue", "flush")
+	if n := queued(t); n != 0 {
+		t.Errorf("%d cheers queued after a flush, want 0", n)
+	}
+	if !cheered(t, ctx, client, "Bob Smith", "queued review") {
+		t.Error("the queued cheer is not on the dashboard")
+	}
+
+	var dashboard api.Dashboard
+	if err := json.Unmarshal(capture(t, "me", "--output", "json"), &dashboard); err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.User.ID != "u1" || len(dashboard.Logs) == 0 || dashboard.Logs[0].Message != "queued review" {
+		t.Errorf("me shows %+v, want the latest cheer first", dashboard)
+	}
+	if _, err := os.Stat(config.DashboardCachePath()); err != nil {
+		t.Errorf("me did not cache the dashboard: %v", err)
+	}
+
+	file := filepath.Join(home, "feats.yml")
+	content := "# managed by the test\n- slug: pairing\n  label: Pair programming\n  karma: 60\n- label: Docs\n  karma: 15\n"
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run(t, "feats", "apply", "--file", file, "--prune", "--yes")
+
+	feats, err := client.ListAllFeats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	active := map[string]api.Feat{}
+	for _, f := range feats {
+		if !f.Archived {
+			active[f.Slug] = f
+		}
+	}
+
+	if len(active) != 2 {
+		t.Errorf("%d active feats after apply, want 2: %+v", len(active), active)
+	}
+	if f := active["pairing"]; f.Label != "Pair programming" || f.Karma != 60 {
+		t.Errorf("pairing = %+v, want it updated", f)
+	}
+	if f, ok := active["docs"]; !ok || f.Karma != 15 {
+		t.Errorf("docs = %+v, want it created", f)
+	}
+}
+
+func cheered(t *testing.T, ctx context.Context, client api.Client, to string, msg string) bool {
+	t.Helper()
+
+	dashboard, err := client.GetDashboard(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range dashboard.Logs {
+		if entry.ToUser == to && entry.Message == msg {
+			return true
+		}
+	}
+
+	return false
+}
+
+func queued(t *testing.T) int {
+	t.Helper()
+
+	q, err := queue.Load(config.QueuePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return len(q.Entries)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completion
+
+import (
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdCompletion creates a completion command
+func NewCmdCompletion() *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "completion <bash|zsh|fish|powershell>",
+		Short: "Generate shell completion scripts",
+		Long: heredoc.Doc(`
+			Generate shell completion scripts.
+
+			Developers and feats complete from the team members and feats cached in your
+			profile, see 'karma sync'.
+		`),
+		Example: heredoc.Doc(`
+			# bash, in ~/.bashrc
+			$ source <(karma completion bash)
+
+			# zsh, with compinit enabled
+			$ karma completion zsh > "${fpath[1]}/_karma"
+
+			# fish
+			$ karma completion fish > ~/.config/fish/completions/karma.fish
+
+			# powershell, in your profile
+			PS> karma completion powershell | Out-String | Invoke-Expression
+		`),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		Args:      cobra.ExactValidArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletion(os.Stdout)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			default:
+				return root.GenPowerShellCompletion(os.Stdout)
+			}
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
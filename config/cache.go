@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/krmdv/cli/api"
+)
+
+// DefaultCacheTTL is how long cached team members and feats are used before being refreshed
+const DefaultCacheTTL = time.Hour
+
+// backgroundRefreshGrace is how long a command waits, once done, for a background refresh to complete
+const backgroundRefreshGrace = 2 * time.Second
+
+// TeamCache is the team data kept in the configuration, so commands don't wait for the API
+type TeamCache struct {
+	Team  api.Team
+	Feats []api.Feat
+}
+
+// CacheTTL returns how long the team cache is fresh, set with KARMA_CACHE_TTL (e.g. "30m", "0" to
+// only refresh with 'karma sync')
+func CacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("KARMA_CACHE_TTL"))
+
+	if err != nil || ttl < 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	return ttl
+}
+
+// CacheStale tells whether cached team members and feats are due for a refresh
+func CacheStale() bool {
+	ttl := CacheTTL()
+
+	return ttl > 0 && time.Since(SyncedAt()) > ttl
+}
+
+// FetchTeamCache gets the current team members and feats from the API
+func FetchTeamCache(ctx context.Context, client api.Client) (TeamCache, error) {
+	var cache TeamCache
+
+	team, err := client.GetTeam(ctx)
+	if err != nil {
+		return cache, err
+	}
+
+	feats, err := client.ListFeats(ctx)
+	if err != nil {
+		return cache, err
+	}
+
+	cache.Team = team
+	cache.Feats = feats
+
+	return cache, nil
+}
+
+// SaveTeamCache saves team members and feats in the configuration of the active profile
+func SaveTeamCache(cache TeamCache) error {
+	Set("team.name", cache.Team.Name)
+	Set("users", cache.Team.Users)
+	Set("feats", cache.Feats)
+	Set("synced_at", time.Now())
+
+	return Write()
+}
+
+// SaveFeats saves the feats of the active profile after changing some, leaving the sync time alone
+func SaveFeats(feats []api.Feat) error {
+	Set("feats", feats)
+
+	return Write()
+}
+
+// SaveUsers saves the team members of the active profile after changing some, leaving the sync time alone
+func SaveUsers(users []api.User) error {
+	Set("users", users)
+
+	return Write()
+}
+
+// RefreshInBackground fetches team members and feats while a command runs, and returns a function
+// saving them once it is done. Saving waits a little for the API, then gives up silently: the
+// cache is refreshed by a later command instead.
+func RefreshInBackground(ctx context.Context, client api.Client) func() {
+	done := make(chan error, 1)
+
+	var cache TeamCache
+	go func() {
+		var err error
+		cache, err = FetchTeamCache(ctx, client.With(api.WithRetries(0)))
+		done <- err
+	}()
+
+	return func() {
+		select {
+		case err := <-done:
+			if err == nil {
+				SaveTeamCache(cache)
+			}
+		case <-time.After(backgroundRefreshGrace):
+		}
+	}
+}
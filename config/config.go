@@ -4,33 +4,35 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/krmdv/cli/api"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
 // Configuration is the
 type Configuration struct {
+	Host  string `mapstructure:"host"`
 	Token string `mapstructure:"token"`
-	Team  struct {
-		ID string `mapstructure:"id"`
-	} `mapstructure:"team"`
-	Users []struct {
+	User  struct {
+		ID   string `mapstructure:"id"`
 		Name string `mapstructure:"name"`
+	} `mapstructure:"user"`
+	Team struct {
 		ID   string `mapstructure:"id"`
-	} `mapstructure:"users"`
-	Feats []struct {
-		ID    string `mapstructure:"id"`
-		Label string `mapstructure:"label"`
-		Slug  string `mapstructure:"slug"`
-		Karma int    `mapstructure:"karma"`
-	} `mapstructure:"feats"`
+		Name string `mapstructure:"name"`
+	} `mapstructure:"team"`
+	Users []api.User `mapstructure:"users"`
+	Feats []api.Feat `mapstructure:"feats"`
 }
 
 // CheckAuthed ensures user has setup an API token
 func CheckAuthed() error {
-	if token := viper.GetString("token"); token == "" {
-		return errors.New("no token present, please run 'karma config --token xxx' first")
+	if token == "" {
+		return errors.New("not logged in, please run 'karma login' first")
 	}
 
 	return nil
@@ -38,8 +40,8 @@ func CheckAuthed() error {
 
 // CheckLoaded ensures configuration has been loaded
 func CheckLoaded() error {
-	if token := viper.GetString("token"); token == "" {
-		return errors.New("no token present, please run 'karma config --token xxx' first")
+	if token == "" {
+		return errors.New("not logged in, please run 'karma login' first")
 	}
 
 	if teamID := viper.GetString("team.id"); teamID == "" {
@@ -49,19 +51,67 @@ func CheckLoaded() error {
 	return nil
 }
 
+// SyncedAt returns when the team's users and feats were last saved in the configuration
+func SyncedAt() time.Time {
+	return viper.GetTime("synced_at")
+}
+
+// DefaultHost is the base Karma API endpoint, unless set by the profile or KARMA_HOST
+const DefaultHost = "https://api.getkarma.dev"
+
 // Host returns the base Karma API endpoint
 func Host() string {
 	host := os.Getenv("KARMA_HOST")
 
 	if host == "" {
-		host = "https://api.getkarma.dev"
+		host = viper.GetString("host")
+	}
+
+	if host == "" {
+		host = DefaultHost
 	}
 
 	return host
 }
 
-// Get returns a configuration object
-func Get() Configuration {
+// Timeout returns the time limit for API calls, set with KARMA_TIMEOUT (e.g. "10s")
+func Timeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("KARMA_TIMEOUT"))
+
+	if err != nil || timeout <= 0 {
+		timeout = api.DefaultTimeout
+	}
+
+	return timeout
+}
+
+// Retries returns how many times failed idempotent API calls are retried, set with KARMA_RETRIES
+func Retries() int {
+	retries, err := strconv.Atoi(os.Getenv("KARMA_RETRIES"))
+
+	if err != nil || retries < 0 {
+		retries = api.DefaultRetries
+	}
+
+	return retries
+}
+
+// Path returns the path of the configuration file of the active profile
+func Path() string {
+	return ProfilePath(Profile())
+}
+
+// QueuePath returns the path of the file holding cheers waiting to be sent
+func QueuePath() string {
+	return profileFile(Profile(), "-queue.json")
+}
+
+// DashboardCachePath returns the path of the file holding the last dashboard fetched
+func DashboardCachePath() string {
+	return profileFile(Profile(), "-dashboard.json")
+}
+
+func homePath(name string) string {
 	home, err := homedir.Dir()
 
 	if err != nil {
@@ -69,14 +119,19 @@ func Get() Configuration {
 		os.Exit(1)
 	}
 
-	viper.AddConfigPath(home)
-	viper.SetConfigName(".karma")
+	return filepath.Join(home, name)
+}
+
+// Get returns a configuration object
+func Get() Configuration {
+	viper.SetConfigFile(Path())
 	viper.SetConfigType("yaml")
 
+	viper.SetEnvPrefix("karma")
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		if os.IsNotExist(err) {
 			// no issue, we'll warn users about missing conf when running commands
 		} else {
 			fmt.Println(err)
@@ -84,8 +139,13 @@ func Get() Configuration {
 		}
 	}
 
+	warnLoosePermissions()
+
 	var conf Configuration
 	viper.Unmarshal(&conf)
 
+	token = loadToken()
+	conf.Token = token
+
 	return conf
 }
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/credential"
+	"github.com/spf13/viper"
+)
+
+// secretKeys are the configuration keys whose values live in the credential store
+var secretKeys = []string{"token", "team.token"}
+
+// token is the API token resolved by Get, from KARMA_TOKEN or the credential store
+var token string
+
+// CredentialStore returns the credential store new secrets are saved to: the one requested with
+// KARMA_CREDENTIAL_STORE ("keyring", "file", "helper:<name>" or "auto"), else the one the active
+// profile already uses, else "auto"
+func CredentialStore() string {
+	if store := os.Getenv("KARMA_CREDENTIAL_STORE"); store != "" {
+		return store
+	}
+
+	if store := viper.GetString("credential.store"); store != "" {
+		return store
+	}
+
+	return credential.Auto
+}
+
+// TokenSource describes where the API token comes from, or returns "" when there is none
+func TokenSource() string {
+	switch {
+	case os.Getenv("KARMA_TOKEN") != "":
+		return "KARMA_TOKEN"
+	case token != "":
+		return fmt.Sprintf("the %s credential store", viper.GetString("credential.store"))
+	}
+
+	return ""
+}
+
+// SetSecret saves a secret of the active profile in its credential store and records which store
+// holds it in the configuration
+func SetSecret(name string, secret string) error {
+	store := CredentialStore()
+
+	candidates, err := credential.Candidates(store, credentialOptions())
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range candidates {
+		if err = candidate.Set(secretKey(Profile(), name), secret); err == nil {
+			Set("credential.store", candidate.Name())
+			Set(name, "")
+			return nil
+		}
+	}
+
+	return err
+}
+
+// Secret returns a secret of the active profile from its credential store
+func Secret(name string) (string, error) {
+	store := viper.GetString("credential.store")
+	if store == "" {
+		return "", credential.ErrNotFound
+	}
+
+	s, err := credential.Open(store, credentialOptions())
+	if err != nil {
+		return "", err
+	}
+
+	return s.Get(secretKey(Profile(), name))
+}
+
+// DeleteSecrets removes the secrets of the active profile from its credential store, returning the
+// name of the store or "" if it has none
+func DeleteSecrets() (string, error) {
+	store := viper.GetString("credential.store")
+	if store == "" {
+		return "", nil
+	}
+
+	return store, deleteSecrets(Profile(), store)
+}
+
+// deleteSecrets removes the secrets of a profile from a credential store
+func deleteSecrets(profile string, store string) error {
+	s, err := credential.Open(store, credentialOptions())
+	if err != nil {
+		return err
+	}
+
+	for _, name := range secretKeys {
+		if err := s.Delete(secretKey(profile, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func secretKey(profile string, name string) string {
+	return profile + "/" + name
+}
+
+func credentialOptions() credential.Options {
+	return credential.Options{
+		FilePath: homePath(".karma-credentials"),
+		Host:     Host(),
+	}
+}
+
+// loadToken resolves the API token of the active profile, moving it to a credential store first
+// if the configuration file still holds it in plain text
+func loadToken() string {
+	if token := os.Getenv("KARMA_TOKEN"); token != "" {
+		return token
+	}
+
+	if viper.GetString("credential.store") == "" {
+		migrateSecrets()
+	}
+
+	token, err := Secret("token")
+	if err != nil && !errors.Is(err, credential.ErrNotFound) {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "Heads up! Couldn't read your token from the %s credential store: %v\n", viper.GetString("credential.store"), err)
+	}
+
+	return token
+}
+
+// migrateSecrets moves secrets saved in plain text by previous versions to a credential store
+func migrateSecrets() {
+	moved := false
+
+	for _, name := range secretKeys {
+		secret, ok := viper.Get(name).(string)
+		if !ok || secret == "" {
+			continue
+		}
+
+		if err := SetSecret(name, secret); err != nil {
+			color.New(color.FgYellow).Fprintf(os.Stderr, "Heads up! Your %s is stored in plain text in %s and couldn't be moved to a credential store: %v\n", name, Path(), err)
+			return
+		}
+		moved = true
+	}
+
+	if !moved {
+		return
+	}
+
+	if err := Write(); err != nil {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "Heads up! Couldn't update %s: %v\n", Path(), err)
+		return
+	}
+
+	color.New(color.FgYellow).Fprintf(os.Stderr, "Moved your token from %s to the %s credential store.\n", Path(), viper.GetString("credential.store"))
+}
+
+// setting is a configuration key changed with Set
+type setting struct {
+	key   string
+	value interface{}
+}
+
+// settings are the keys changed since the configuration was loaded, in order. Only they are
+// written over the configuration file, so values coming from the environment are never saved.
+var settings []setting
+
+// Set changes a configuration key of the active profile, saved by Write
+func Set(key string, value interface{}) {
+	viper.Set(key, value)
+	settings = append(settings, setting{key, value})
+}
+
+// Write saves the keys changed with Set to the configuration file of the active profile, readable
+// by the current user only
+func Write() error {
+	v := viper.New()
+	v.SetConfigFile(Path())
+	v.SetConfigType("yaml")
+
+	if err := v.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, s := range settings {
+		v.Set(s.key, s.value)
+	}
+
+	v.SetConfigPermissions(0600)
+
+	// permissions only apply to new files, tighten those written by previous versions
+	if err := v.WriteConfigAs(Path()); err != nil {
+		return err
+	}
+
+	return os.Chmod(Path(), 0600)
+}
+
+// warnLoosePermissions warns when the configuration file can be read by other users
+func warnLoosePermissions() {
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	info, err := os.Stat(Path())
+	if err != nil || info.Mode().Perm()&0077 == 0 {
+		return
+	}
+
+	color.New(color.FgYellow).Fprintln(os.Stderr, fmt.Sprintf("Heads up! %s is readable by other users, run 'chmod 600 %s'.", Path(), Path()))
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+// setup points the configuration to a temporary home using the file credential store, and
+// forgets what previous tests loaded
+func setup(t *testing.T, vars map[string]string) string {
+	home := t.TempDir()
+	homedir.DisableCache = true
+	viper.Reset()
+	settings = nil
+
+	env := map[string]string{
+		"HOME":                        home,
+		"KARMA_CREDENTIAL_STORE":      "file",
+		"KARMA_CREDENTIAL_PASSPHRASE": "test",
+		"KARMA_TOKEN":                 "",
+		"KARMA_PROFILE":               "",
+	}
+	for name, value := range vars {
+		env[name] = value
+	}
+
+	for name, value := range env {
+		previous, set := os.LookupEnv(name)
+		if value == "" {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, value)
+		}
+
+		name := name
+		t.Cleanup(func() {
+			if set {
+				os.Setenv(name, previous)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+
+	return home
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	setup(t, nil)
+
+	plain := "token: plain-token\nuser:\n  id: u1\nteam:\n  id: t1\n  token: team-token\n"
+	if err := ioutil.WriteFile(Path(), []byte(plain), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := Get()
+	if conf.Token != "plain-token" {
+		t.Errorf("Get() token = %q, want plain-token", conf.Token)
+	}
+
+	content, err := ioutil.ReadFile(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"plain-token", "team-token"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("%s still holds %s:\n%s", Path(), secret, content)
+		}
+	}
+	if !strings.Contains(string(content), "id: u1") {
+		t.Errorf("%s lost the other settings:\n%s", Path(), content)
+	}
+
+	info, err := os.Stat(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("%s has mode %v, want 0600", Path(), info.Mode().Perm())
+	}
+
+	for name, want := range map[string]string{"token": "plain-token", "team.token": "team-token"} {
+		if got, err := Secret(name); err != nil || got != want {
+			t.Errorf("Secret(%s) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+
+	// the next run finds the token in the credential store
+	viper.Reset()
+	if conf := Get(); conf.Token != "plain-token" {
+		t.Errorf("Get() token after migration = %q, want plain-token", conf.Token)
+	}
+}
+
+func TestWriteSkipsEnvironment(t *testing.T) {
+	home := setup(t, map[string]string{"KARMA_TOKEN": "env-token", "KARMA_HOST": "http://env-host"})
+
+	if err := ioutil.WriteFile(Path(), []byte("team:\n  id: t1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if conf := Get(); conf.Token != "env-token" {
+		t.Errorf("Get() token = %q, want env-token", conf.Token)
+	}
+
+	Set("user.name", "you")
+	if err := Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "env-") || !strings.Contains(string(content), "name: you") {
+		t.Errorf("%s = \n%s, want only the changed key added", Path(), content)
+	}
+
+	if _, err := os.Stat(filepath.Join(home, ".karma-credentials")); !os.IsNotExist(err) {
+		t.Errorf("KARMA_TOKEN was saved to the credential store: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultProfile is the profile stored in ~/.karma.yaml
+const DefaultProfile = "default"
+
+// profileFlag is the profile selected with --profile, if any
+var profileFlag string
+
+var profileNameRE = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// SetProfile selects the profile to use for this run, overriding KARMA_PROFILE and 'karma profile use'
+func SetProfile(name string) {
+	profileFlag = name
+}
+
+// Profile returns the name of the active profile
+func Profile() string {
+	if profileFlag != "" {
+		return profileFlag
+	}
+
+	if name := os.Getenv("KARMA_PROFILE"); name != "" {
+		return name
+	}
+
+	if content, err := ioutil.ReadFile(homePath(".karma-profile")); err == nil {
+		if name := strings.TrimSpace(string(content)); name != "" {
+			return name
+		}
+	}
+
+	return DefaultProfile
+}
+
+// UseProfile makes a profile the active one for later runs
+func UseProfile(name string) error {
+	if name == DefaultProfile {
+		if err := os.Remove(homePath(".karma-profile")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	return ioutil.WriteFile(homePath(".karma-profile"), []byte(name+"\n"), 0600)
+}
+
+// ValidateProfileName ensures a profile name can be used in file names
+func ValidateProfileName(name string) error {
+	if !profileNameRE.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, use letters, digits, '-' and '_' only", name)
+	}
+
+	return nil
+}
+
+// Profiles returns the names of existing profiles, sorted
+func Profiles() ([]string, error) {
+	matches, err := filepath.Glob(homePath(".karma.*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if _, err := os.Stat(ProfilePath(DefaultProfile)); err == nil {
+		names = append(names, DefaultProfile)
+	}
+
+	for _, m := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), ".karma."), ".yaml")
+		if profileNameRE.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// ProfileExists tells whether a profile has a configuration file
+func ProfileExists(name string) bool {
+	_, err := os.Stat(ProfilePath(name))
+
+	return err == nil
+}
+
+// ReadProfile returns the configuration of a profile without activating it
+func ReadProfile(name string) (Configuration, error) {
+	var conf Configuration
+
+	v := viper.New()
+	v.SetConfigFile(ProfilePath(name))
+	v.SetConfigType("yaml")
+
+	if err := v.ReadInConfig(); err != nil {
+		return conf, err
+	}
+
+	// read keys one by one, login resets nested sections to empty strings
+	conf.Host = v.GetString("host")
+	conf.Token = v.GetString("token")
+	conf.User.ID = v.GetString("user.id")
+	conf.User.Name = v.GetString("user.name")
+	conf.Team.ID = v.GetString("team.id")
+	conf.Team.Name = v.GetString("team.name")
+
+	// cached users and feats are reset to empty strings on login too
+	_ = v.UnmarshalKey("users", &conf.Users)
+	_ = v.UnmarshalKey("feats", &conf.Feats)
+
+	return conf, nil
+}
+
+// CreateProfile creates an empty profile, optionally bound to an API host
+func CreateProfile(name string, host string) error {
+	if ProfileExists(name) {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+
+	v := viper.New()
+	v.SetConfigPermissions(0600)
+	if host != "" {
+		v.Set("host", host)
+	}
+
+	return v.WriteConfigAs(ProfilePath(name))
+}
+
+// ProfilePath returns the path of the configuration file of a profile
+func ProfilePath(name string) string {
+	return profileFile(name, ".yaml")
+}
+
+// RemoveProfile deletes the configuration and local state of a profile
+func RemoveProfile(name string) error {
+	if !ProfileExists(name) {
+		return fmt.Errorf("no profile named %q", name)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(ProfilePath(name))
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+
+	if store := v.GetString("credential.store"); store != "" {
+		if err := deleteSecrets(name, store); err != nil {
+			return fmt.Errorf("could not delete the credentials of profile %q: %w", name, err)
+		}
+	}
+
+	for _, suffix := range profileFileSuffixes {
+		if err := os.Remove(profileFile(name, suffix)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if Profile() == name {
+		return UseProfile(DefaultProfile)
+	}
+
+	return nil
+}
+
+// profileFileSuffixes are the files kept per profile, next to its configuration
+var profileFileSuffixes = []string{".yaml", "-queue.json", "-dashboard.json"}
+
+// profileFile returns the path of a file belonging to a profile: ~/.karma<suffix> for
+// the default profile, ~/.karma.<name><suffix> for others
+func profileFile(name string, suffix string) string {
+	if name == DefaultProfile {
+		return homePath(".karma" + suffix)
+	}
+
+	return homePath(".karma." + name + suffix)
+}
@@ -0,0 +1,197 @@
+package credential
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// PassphraseEnv names the environment variable holding the passphrase of the encrypted file
+// store. Without it, a random key is kept next to the file, which then only keeps secrets from
+// leaking with a copy of the file alone: anyone able to read both can decrypt it.
+const PassphraseEnv = "KARMA_CREDENTIAL_PASSPHRASE"
+
+// file stores secrets in a JSON document encrypted with AES-GCM, with a key derived from a
+// passphrase or a random key file
+type file struct {
+	path string
+}
+
+// sealedFile is the on-disk format of the encrypted file store
+type sealedFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func newFile(path string) Store {
+	return &file{path: path}
+}
+
+func (f *file) Name() string {
+	return "file"
+}
+
+func (f *file) Get(key string) (string, error) {
+	secrets, err := f.load()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return secret, nil
+}
+
+func (f *file) Set(key string, secret string) error {
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	secrets[key] = secret
+
+	return f.save(secrets)
+}
+
+func (f *file) Delete(key string) error {
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+
+	delete(secrets, key)
+
+	return f.save(secrets)
+}
+
+func (f *file) load() (map[string]string, error) {
+	secrets := map[string]string{}
+
+	content, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sealed sealedFile
+	if err := json.Unmarshal(content, &sealed); err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", f.path, err)
+	}
+
+	aead, err := f.cipher(sealed.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := aead.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt %s, check %s", f.path, PassphraseEnv)
+	}
+
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", f.path, err)
+	}
+
+	return secrets, nil
+}
+
+func (f *file) save(secrets map[string]string) error {
+	if len(secrets) == 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	sealed := sealedFile{Salt: make([]byte, 16)}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return err
+	}
+
+	aead, err := f.cipher(sealed.Salt)
+	if err != nil {
+		return err
+	}
+
+	sealed.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return err
+	}
+	sealed.Data = aead.Seal(nil, sealed.Nonce, plain, nil)
+
+	content, err := json.Marshal(sealed)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(f.path, content, 0600)
+}
+
+func (f *file) cipher(salt []byte) (cipher.AEAD, error) {
+	secret, err := f.passphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key(secret, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// passphrase returns the passphrase from the environment, or the content of the key file,
+// created on first use
+func (f *file) passphrase() ([]byte, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	keyPath := f.path + ".key"
+
+	key, err := ioutil.ReadFile(keyPath)
+	if err == nil {
+		if len(key) == 0 {
+			return nil, errors.New(keyPath + " is empty")
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, ioutil.WriteFile(keyPath, key, 0600)
+}
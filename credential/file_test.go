@@ -0,0 +1,178 @@
+package credential
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// setPassphrase sets the passphrase of the file store for the duration of a test, "" unsets it
+func setPassphrase(t *testing.T, passphrase string) {
+	previous, set := os.LookupEnv(PassphraseEnv)
+	if passphrase == "" {
+		os.Unsetenv(PassphraseEnv)
+	} else {
+		os.Setenv(PassphraseEnv, passphrase)
+	}
+
+	t.Cleanup(func() {
+		if set {
+			os.Setenv(PassphraseEnv, previous)
+		} else {
+			os.Unsetenv(PassphraseEnv)
+		}
+	})
+}
+
+func checkPrivate(t *testing.T, path string) {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("%s has mode %v, want 0600", path, info.Mode().Perm())
+	}
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	for _, passphrase := range []string{"correct horse", ""} {
+		name := "passphrase"
+		if passphrase == "" {
+			name = "key file"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			setPassphrase(t, passphrase)
+			path := filepath.Join(t.TempDir(), "credentials")
+
+			store := newFile(path)
+			if err := store.Set("default/token", "secret-1"); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Set("work/token", "secret-2"); err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(content), "secret-1") {
+				t.Errorf("%s holds a secret in plain text", path)
+			}
+			checkPrivate(t, path)
+			if passphrase == "" {
+				checkPrivate(t, path+".key")
+			}
+
+			// a new store reads what another one wrote
+			store = newFile(path)
+			if got, err := store.Get("default/token"); err != nil || got != "secret-1" {
+				t.Errorf("Get(default/token) = %q, %v, want secret-1", got, err)
+			}
+
+			if err := store.Delete("default/token"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.Get("default/token"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() after Delete() = %v, want ErrNotFound", err)
+			}
+			if got, err := store.Get("work/token"); err != nil || got != "secret-2" {
+				t.Errorf("Get(work/token) = %q, %v, want secret-2", got, err)
+			}
+
+			if err := store.Delete("work/token"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("%s should be removed once empty, got %v", path, err)
+			}
+		})
+	}
+}
+
+func TestFileWrongKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credentials")
+
+	setPassphrase(t, "right")
+	if err := newFile(path).Set("default/token", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	setPassphrase(t, "wrong")
+	if _, err := newFile(path).Get("default/token"); err == nil || !strings.Contains(err.Error(), "could not decrypt") {
+		t.Errorf("Get() with the wrong passphrase = %v, want a decryption error", err)
+	}
+
+	// secrets saved with a key file can't be read with another one
+	setPassphrase(t, "")
+	other := filepath.Join(dir, "other")
+	if err := newFile(other).Set("default/token", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(other+".key", []byte("another key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newFile(other).Get("default/token"); err == nil {
+		t.Error("Get() with another key file succeeded")
+	}
+}
+
+func TestFileCorrupted(t *testing.T) {
+	setPassphrase(t, "test")
+
+	tamper := func(content []byte) []byte {
+		var sealed sealedFile
+		if err := json.Unmarshal(content, &sealed); err != nil {
+			t.Fatal(err)
+		}
+		sealed.Data[0] ^= 0xff
+		content, _ = json.Marshal(sealed)
+		return content
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func(content []byte) []byte
+	}{
+		{"not json", func([]byte) []byte { return []byte("token: secret") }},
+		{"truncated", func(content []byte) []byte { return content[:len(content)/2] }},
+		{"tampered", tamper},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "credentials")
+
+			store := newFile(path)
+			if err := store.Set("default/token", "secret"); err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, tt.corrupt(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := store.Get("default/token"); err == nil || errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() = %v, want an error about %s", err, path)
+			}
+
+			// a corrupted file is never overwritten, which would lose the other secrets
+			if err := store.Set("work/token", "other"); err == nil {
+				t.Error("Set() succeeded over a corrupted file")
+			}
+		})
+	}
+}
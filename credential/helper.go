@@ -0,0 +1,102 @@
+package credential
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// helper delegates secrets to an external program speaking git's credential helper
+// protocol: it is run with "get", "store" or "erase" and reads key=value lines on stdin,
+// "get" answers with a password=<secret> line.
+//
+// A helper named "foo" runs karma-credential-foo from the PATH, an absolute path runs
+// that program and a name starting with "!" runs the rest as a shell command, like git.
+type helper struct {
+	name     string
+	protocol string
+	host     string
+}
+
+func newHelper(name string, host string) (Store, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing credential helper name, use helper:<name>")
+	}
+
+	h := &helper{name: name, protocol: "https", host: host}
+
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		h.protocol = u.Scheme
+		h.host = u.Host
+	}
+
+	return h, nil
+}
+
+func (h *helper) Name() string {
+	return "helper:" + h.name
+}
+
+func (h *helper) Get(key string) (string, error) {
+	out, err := h.run("get", key, "")
+	if err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if secret := strings.TrimPrefix(scanner.Text(), "password="); secret != scanner.Text() {
+			return secret, nil
+		}
+	}
+
+	return "", ErrNotFound
+}
+
+func (h *helper) Set(key string, secret string) error {
+	_, err := h.run("store", key, secret)
+
+	return err
+}
+
+func (h *helper) Delete(key string) error {
+	_, err := h.run("erase", key, "")
+
+	return err
+}
+
+func (h *helper) run(action string, key string, secret string) ([]byte, error) {
+	var cmd *exec.Cmd
+
+	switch {
+	case strings.HasPrefix(h.name, "!"):
+		cmd = exec.Command("sh", "-c", strings.TrimPrefix(h.name, "!")+" "+action)
+	case filepath.IsAbs(h.name):
+		cmd = exec.Command(h.name, action)
+	default:
+		cmd = exec.Command("karma-credential-"+h.name, action)
+	}
+
+	var input bytes.Buffer
+	fmt.Fprintf(&input, "protocol=%s\nhost=%s\nusername=%s\n", h.protocol, h.host, key)
+	if secret != "" {
+		fmt.Fprintf(&input, "password=%s\n", secret)
+	}
+	input.WriteString("\n")
+
+	var stdout bytes.Buffer
+	cmd.Stdin = &input
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential helper %s %s: %w", h.name, action, err)
+	}
+
+	return stdout.Bytes(), nil
+}
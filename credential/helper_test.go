@@ -0,0 +1,96 @@
+package credential
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// stubHelper is a credential helper keeping secrets in a file next to it and recording the input
+// of its last call
+const stubHelper = `#!/bin/sh
+dir=$(dirname "$0")
+input=$(cat)
+printf '%s\n' "$input" > "$dir/last-input"
+user=$(printf '%s\n' "$input" | sed -n 's/^username=//p')
+touch "$dir/secrets"
+case "$1" in
+get)
+	grep "^$user=" "$dir/secrets" | sed "s/^[^=]*=/password=/"
+	;;
+store)
+	secret=$(printf '%s\n' "$input" | sed -n 's/^password=//p')
+	grep -v "^$user=" "$dir/secrets" > "$dir/secrets.new"
+	echo "$user=$secret" >> "$dir/secrets.new"
+	mv "$dir/secrets.new" "$dir/secrets"
+	;;
+erase)
+	grep -v "^$user=" "$dir/secrets" > "$dir/secrets.new"
+	mv "$dir/secrets.new" "$dir/secrets"
+	;;
+esac
+`
+
+func TestHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub helper is a shell script")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "karma-credential-stub")
+	if err := ioutil.WriteFile(script, []byte(stubHelper), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+
+	for _, name := range []string{"stub", script, "!" + script} {
+		t.Run(name, func(t *testing.T) {
+			os.Remove(filepath.Join(dir, "secrets"))
+
+			store, err := Open("helper:"+name, Options{Host: "http://localhost:8089"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := store.Get("default/token"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() before Set() = %v, want ErrNotFound", err)
+			}
+
+			if err := store.Set("default/token", "secret"); err != nil {
+				t.Fatal(err)
+			}
+
+			input, err := ioutil.ReadFile(filepath.Join(dir, "last-input"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "protocol=http\nhost=localhost:8089\nusername=default/token\npassword=secret\n"
+			if !strings.HasPrefix(string(input), want) {
+				t.Errorf("helper got %q, want %q", input, want)
+			}
+
+			if got, err := store.Get("default/token"); err != nil || got != "secret" {
+				t.Errorf("Get() = %q, %v, want secret", got, err)
+			}
+
+			if err := store.Delete("default/token"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.Get("default/token"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() after Delete() = %v, want ErrNotFound", err)
+			}
+		})
+	}
+
+	store, _ := Open("helper:"+filepath.Join(dir, "missing"), Options{})
+	if err := store.Set("default/token", "secret"); err == nil {
+		t.Error("Set() with a missing helper succeeded")
+	}
+}
@@ -0,0 +1,140 @@
+package credential
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// service groups the CLI's entries in the keyring
+const service = "karma-cli"
+
+// keyring stores secrets in the Secret Service on Linux (through secret-tool) or the login
+// keychain on macOS (through security)
+type keyring struct {
+	tool string
+}
+
+func newKeyring() (Store, error) {
+	tool := "secret-tool"
+	if runtime.GOOS == "darwin" {
+		tool = "security"
+	}
+
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return nil, fmt.Errorf("no keyring available on this system, %s not found", tool)
+	}
+
+	return &keyring{tool: path}, nil
+}
+
+func (k *keyring) Name() string {
+	return "keyring"
+}
+
+func (k *keyring) Get(key string) (string, error) {
+	var args []string
+	if runtime.GOOS == "darwin" {
+		args = []string{"find-generic-password", "-s", service, "-a", key, "-w"}
+	} else {
+		args = []string{"lookup", "service", service, "account", key}
+	}
+
+	out, err := k.run("", args...)
+	if isNotFound(err) || err == nil && out == "" {
+		return "", ErrNotFound
+	}
+
+	return out, err
+}
+
+func (k *keyring) Set(key string, secret string) error {
+	if runtime.GOOS == "darwin" {
+		// security reads the command from stdin in interactive mode, keeping the secret out of
+		// the arguments other users can see with ps
+		command := fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -w %s\n", quote(service), quote(key), quote("Karma CLI"), quote(secret))
+		_, err := k.run(command, "-i")
+		return err
+	}
+
+	_, err := k.run(secret, "store", "--label", "Karma CLI ("+key+")", "service", service, "account", key)
+
+	return err
+}
+
+func (k *keyring) Delete(key string) error {
+	var args []string
+	if runtime.GOOS == "darwin" {
+		args = []string{"delete-generic-password", "-s", service, "-a", key}
+	} else {
+		args = []string{"clear", "service", service, "account", key}
+	}
+
+	if _, err := k.run("", args...); err != nil && !isNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (k *keyring) run(stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(k.tool, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", &toolError{msg: strings.TrimSpace(stderr.String()), err: err}
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// quote escapes an argument of a command run by security in interactive mode
+func quote(arg string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// toolError is a failure of the keyring tool, with what it printed on stderr
+type toolError struct {
+	msg string
+	err error
+}
+
+func (e *toolError) Error() string {
+	if e.msg == "" {
+		return fmt.Sprintf("keyring: %v", e.err)
+	}
+
+	return fmt.Sprintf("keyring: %s", e.msg)
+}
+
+func (e *toolError) Unwrap() error {
+	return e.err
+}
+
+// isNotFound tells whether the tool failed because no entry matched: secret-tool exits
+// silently, security exits with code 44
+func isNotFound(err error) bool {
+	var toolErr *toolError
+	if !errors.As(err, &toolErr) {
+		return false
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(toolErr.err, &exitErr) {
+		return false
+	}
+
+	if runtime.GOOS == "darwin" {
+		return exitErr.ExitCode() == 44
+	}
+
+	return toolErr.msg == ""
+}
@@ -0,0 +1,66 @@
+// Package credential keeps API tokens out of the configuration file, in the OS keyring,
+// an external credential helper or an encrypted file.
+package credential
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned when a store holds no secret for a key
+var ErrNotFound = errors.New("credential not found")
+
+// Store holds secrets by key
+type Store interface {
+	// Name is the reference saved in the configuration to find the store again, e.g. "keyring"
+	Name() string
+	Get(key string) (string, error)
+	Set(key string, secret string) error
+	Delete(key string) error
+}
+
+// Options configure stores that need more than a name
+type Options struct {
+	// FilePath is where the encrypted file store keeps secrets
+	FilePath string
+	// Host is the API host, passed to credential helpers
+	Host string
+}
+
+// Auto picks the keyring when one is usable, or the encrypted file otherwise
+const Auto = "auto"
+
+// Open returns the store designated by a name: "keyring", "file" or "helper:<name>"
+func Open(name string, opts Options) (Store, error) {
+	switch {
+	case name == "keyring":
+		return newKeyring()
+	case name == "file":
+		return newFile(opts.FilePath), nil
+	case strings.HasPrefix(name, "helper:"):
+		return newHelper(strings.TrimPrefix(name, "helper:"), opts.Host)
+	}
+
+	return nil, fmt.Errorf("unknown credential store %q, use keyring, file or helper:<name>", name)
+}
+
+// Candidates returns the stores to try, in order, to save a secret in the store designated by
+// a name, which may be "auto"
+func Candidates(name string, opts Options) ([]Store, error) {
+	if name != Auto && name != "" {
+		store, err := Open(name, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Store{store}, nil
+	}
+
+	var stores []Store
+	if keyring, err := newKeyring(); err == nil {
+		stores = append(stores, keyring)
+	}
+
+	return append(stores, newFile(opts.FilePath)), nil
+}
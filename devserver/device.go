@@ -0,0 +1,151 @@
+package devserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/krmdv/cli/api"
+)
+
+const (
+	deviceCodeExpiry = 10 * time.Minute
+	devicePollEvery  = time.Second
+)
+
+// deviceGrant is a device authorization waiting for approval on the /device page
+type deviceGrant struct {
+	userCode string
+	expires  time.Time
+	lastPoll time.Time
+	approved bool
+	denied   bool
+}
+
+// deviceGrants are kept in memory only, pending logins don't survive restarts
+type deviceGrants struct {
+	mu     sync.Mutex
+	grants map[string]*deviceGrant
+}
+
+func (s *Server) startDeviceLogin(w http.ResponseWriter, r *http.Request) {
+	deviceCode, userCode := randomHex(20), strings.ToUpper(randomHex(2)+"-"+randomHex(2))
+
+	s.devices.mu.Lock()
+	if s.devices.grants == nil {
+		s.devices.grants = map[string]*deviceGrant{}
+	}
+	s.devices.grants[deviceCode] = &deviceGrant{userCode: userCode, expires: time.Now().Add(deviceCodeExpiry)}
+	s.devices.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, api.DeviceCode{
+		DeviceCode:      deviceCode,
+		UserCode:        userCode,
+		VerificationURL: "http://" + r.Host + "/device",
+		ExpiresIn:       int(deviceCodeExpiry.Seconds()),
+		Interval:        int(devicePollEvery.Seconds()),
+	})
+}
+
+func (s *Server) pollDeviceLogin(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		DeviceCode string `json:"deviceCode"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.devices.mu.Lock()
+	defer s.devices.mu.Unlock()
+
+	grant, ok := s.devices.grants[payload.DeviceCode]
+	switch {
+	case !ok:
+		writeError(w, http.StatusBadRequest, "unknown device code")
+	case time.Now().After(grant.expires):
+		delete(s.devices.grants, payload.DeviceCode)
+		writeError(w, http.StatusBadRequest, "the device code expired, please login again")
+	case grant.denied:
+		delete(s.devices.grants, payload.DeviceCode)
+		writeError(w, http.StatusBadRequest, "the login was denied")
+	case grant.approved:
+		delete(s.devices.grants, payload.DeviceCode)
+
+		var token string
+		s.store.view(func(f *fixtures) { token = f.Token })
+
+		writeJSON(w, http.StatusOK, api.DeviceToken{Status: api.DeviceApproved, Token: token})
+	case time.Since(grant.lastPoll) < devicePollEvery:
+		grant.lastPoll = time.Now()
+		writeJSON(w, http.StatusOK, api.DeviceToken{Status: api.DeviceSlowDown})
+	default:
+		grant.lastPoll = time.Now()
+		writeJSON(w, http.StatusOK, api.DeviceToken{Status: api.DevicePending})
+	}
+}
+
+// revokeToken acknowledges logouts, the dev token stays valid so fixtures remain usable
+func (s *Server) revokeToken(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var devicePage = template.Must(template.New("device").Parse(`<!doctype html>
+<title>Karma dev server</title>
+<h1>Approve a device</h1>
+{{if .Message}}<p>{{.Message}}</p>{{end}}
+<form method="post">
+  <input name="user_code" placeholder="XXXX-XXXX" value="{{.Code}}" autofocus>
+  <button name="action" value="approve">Approve</button>
+  <button name="action" value="deny">Deny</button>
+</form>
+`))
+
+// device is the page where users enter the code shown by 'karma login'
+func (s *Server) device(w http.ResponseWriter, r *http.Request) {
+	var page struct {
+		Code    string
+		Message string
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		page.Code = r.URL.Query().Get("code")
+	case http.MethodPost:
+		code := strings.ToUpper(strings.TrimSpace(r.FormValue("user_code")))
+		deny := r.FormValue("action") == "deny"
+
+		s.devices.mu.Lock()
+		page.Message = fmt.Sprintf("No pending login with code %s.", code)
+		for _, grant := range s.devices.grants {
+			if grant.userCode == code && time.Now().Before(grant.expires) {
+				grant.approved, grant.denied = !deny, deny
+				page.Message = fmt.Sprintf("Device %s approved, you can go back to your terminal.", code)
+				if deny {
+					page.Message = fmt.Sprintf("Device %s denied.", code)
+				}
+			}
+		}
+		s.devices.mu.Unlock()
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed on /device", r.Method))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	devicePage.Execute(w, page)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
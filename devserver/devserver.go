@@ -0,0 +1,75 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdDevServer creates a dev-server command
+func NewCmdDevServer() *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "dev-server",
+		Short: "Run a local mock of the Karma API",
+		Long: heredoc.Doc(`
+			Run a local mock of the Karma API for offline development.
+
+			State is read from and saved to a JSON fixtures file, which is seeded
+			with a sample team on first run. Point the CLI to it with KARMA_HOST.
+		`),
+		Example: heredoc.Doc(`
+			# start the server, then login with the seeded token
+			$ karma dev-server --addr localhost:8080
+			$ export KARMA_HOST=http://localhost:8080
+			$ karma login dev-token
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, _ := cmd.Flags().GetString("addr")
+			fixtures, _ := cmd.Flags().GetString("fixtures")
+			failureRate, _ := cmd.Flags().GetFloat64("failure-rate")
+
+			return devServerRun(addr, fixtures, failureRate)
+		},
+	}
+
+	cmd.SilenceUsage = true
+	cmd.Flags().String("addr", "localhost:8080", "address to listen on")
+	cmd.Flags().String("fixtures", "karma-fixtures.json", "path to the JSON fixtures file")
+	cmd.Flags().Float64("failure-rate", 0, "share of requests to fail with a 503, between 0 and 1")
+
+	return cmd
+}
+
+func devServerRun(addr string, fixtures string, failureRate float64) error {
+	store, err := OpenStore(fixtures)
+	if err != nil {
+		return err
+	}
+
+	color.Green(fmt.Sprintf("🛠  Karma dev server listening on http://%s (fixtures: %s)", addr, fixtures))
+
+	server := NewServer(store)
+	server.FailureRate = failureRate
+
+	return http.ListenAndServe(addr, server)
+}
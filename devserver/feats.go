@@ -0,0 +1,270 @@
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/krmdv/cli/api"
+)
+
+var slugRE = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func (s *Server) listFeats(w http.ResponseWriter, r *http.Request) {
+	archived := r.URL.Query().Get("archived") == "true"
+
+	feats := []api.Feat{}
+	s.store.view(func(f *fixtures) {
+		for _, ft := range f.Feats {
+			if !ft.Archived || archived {
+				feats = append(feats, f.withUses(ft))
+			}
+		}
+	})
+
+	writeJSON(w, http.StatusOK, feats)
+}
+
+func (s *Server) createFeat(w http.ResponseWriter, r *http.Request) {
+	var payload api.Feat
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var resp api.Feat
+
+	err := s.store.update(func(f *fixtures) error {
+		if err := f.checkAdmin("create feats"); err != nil {
+			return err
+		}
+
+		ft := api.Feat{ID: f.nextFeatID(), Label: payload.Label, Slug: payload.Slug, Karma: payload.Karma}
+		if err := f.validateFeat(ft); err != nil {
+			return err
+		}
+
+		f.Feats = append(f.Feats, ft)
+		resp = ft
+
+		return nil
+	})
+
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// feat serves /feats/<id> and /feats/<id>/archive
+func (s *Server) feat(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/feats/")
+
+	switch {
+	case r.Method == http.MethodPatch && !strings.Contains(id, "/"):
+		s.updateFeat(w, r, id)
+	case r.Method == http.MethodPost && strings.HasSuffix(id, "/archive"):
+		s.archiveFeat(w, r, strings.TrimSuffix(id, "/archive"))
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no route for %s %s", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) updateFeat(w http.ResponseWriter, r *http.Request, id string) {
+	var payload api.FeatChanges
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var resp api.Feat
+
+	err := s.store.update(func(f *fixtures) error {
+		if err := f.checkAdmin("edit feats"); err != nil {
+			return err
+		}
+
+		i := f.featIndex(id)
+		if i < 0 {
+			return httpError{http.StatusNotFound, "no such feat"}
+		}
+
+		ft := changeFeat(f.Feats[i], payload)
+		if err := f.validateFeat(ft); err != nil {
+			return err
+		}
+
+		f.Feats[i] = ft
+		resp = f.withUses(ft)
+
+		return nil
+	})
+
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) archiveFeat(w http.ResponseWriter, r *http.Request, id string) {
+	var resp api.Feat
+
+	err := s.store.update(func(f *fixtures) error {
+		if err := f.checkAdmin("archive feats"); err != nil {
+			return err
+		}
+
+		i := f.featIndex(id)
+		if i < 0 {
+			return httpError{http.StatusNotFound, "no such feat"}
+		}
+
+		f.Feats[i].Archived = true
+		resp = f.withUses(f.Feats[i])
+
+		return nil
+	})
+
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// applyFeats applies a plan to a copy of the feats, so they are only replaced if all changes are valid
+func (s *Server) applyFeats(w http.ResponseWriter, r *http.Request) {
+	var payload api.FeatPlan
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := []api.Feat{}
+
+	err := s.store.update(func(f *fixtures) error {
+		if err := f.checkAdmin("change feats"); err != nil {
+			return err
+		}
+
+		planned := fixtures{Feats: append([]api.Feat{}, f.Feats...)}
+
+		for _, u := range payload.Update {
+			i := planned.featIndex(u.ID)
+			if i < 0 {
+				return httpError{http.StatusNotFound, fmt.Sprintf("no feat with id %s", u.ID)}
+			}
+			planned.Feats[i] = changeFeat(planned.Feats[i], u.FeatChanges)
+		}
+
+		for _, id := range payload.Archive {
+			i := planned.featIndex(id)
+			if i < 0 {
+				return httpError{http.StatusNotFound, fmt.Sprintf("no feat with id %s", id)}
+			}
+			planned.Feats[i].Archived = true
+		}
+
+		for _, ft := range payload.Create {
+			planned.Feats = append(planned.Feats, api.Feat{ID: planned.nextFeatID(), Label: ft.Label, Slug: ft.Slug, Karma: ft.Karma})
+		}
+
+		for _, ft := range planned.Feats {
+			if err := planned.validateFeat(ft); err != nil {
+				return err
+			}
+		}
+
+		f.Feats = planned.Feats
+		for _, ft := range f.Feats {
+			if !ft.Archived {
+				resp = append(resp, f.withUses(ft))
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func changeFeat(ft api.Feat, changes api.FeatChanges) api.Feat {
+	if changes.Label != nil {
+		ft.Label = *changes.Label
+	}
+	if changes.Slug != nil {
+		ft.Slug = *changes.Slug
+	}
+	if changes.Karma != nil {
+		ft.Karma = *changes.Karma
+	}
+	if changes.Archived != nil {
+		ft.Archived = *changes.Archived
+	}
+
+	return ft
+}
+
+// validateFeat checks a new or changed feat against the other feats of the team
+func (f *fixtures) validateFeat(ft api.Feat) error {
+	switch {
+	case strings.TrimSpace(ft.Label) == "":
+		return httpError{http.StatusUnprocessableEntity, "label is required"}
+	case !slugRE.MatchString(ft.Slug):
+		return httpError{http.StatusUnprocessableEntity, fmt.Sprintf("slug %q must be lowercase letters, digits and dashes", ft.Slug)}
+	case ft.Karma == 0:
+		return httpError{http.StatusUnprocessableEntity, "karma must not be zero"}
+	}
+
+	for _, other := range f.Feats {
+		if other.ID != ft.ID && other.Slug == ft.Slug {
+			return httpError{http.StatusConflict, fmt.Sprintf("slug %q is already used by %s", ft.Slug, other.Label)}
+		}
+	}
+
+	return nil
+}
+
+func (f *fixtures) featIndex(id string) int {
+	for i, ft := range f.Feats {
+		if ft.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (f *fixtures) nextFeatID() string {
+	for n := len(f.Feats) + 1; ; n++ {
+		if id := fmt.Sprintf("f%d", n); f.featIndex(id) < 0 {
+			return id
+		}
+	}
+}
+
+func (f *fixtures) withUses(ft api.Feat) api.Feat {
+	ft.Uses = 0
+	for _, c := range f.Cheers {
+		if c.FeatID == ft.ID {
+			ft.Uses++
+		}
+	}
+
+	return ft
+}
@@ -0,0 +1,124 @@
+package devserver
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/krmdv/cli/api"
+)
+
+// periodLengths are the rolling windows of leaderboard periods, all time being compared with a week ago
+var periodLengths = map[string]time.Duration{
+	api.PeriodWeek:    7 * 24 * time.Hour,
+	api.PeriodMonth:   30 * 24 * time.Hour,
+	api.PeriodQuarter: 90 * 24 * time.Hour,
+	api.PeriodAll:     0,
+}
+
+func (s *Server) getRanking(w http.ResponseWriter, r *http.Request) {
+	period, by := r.URL.Query().Get("period"), r.URL.Query().Get("by")
+	if period == "" {
+		period = api.PeriodWeek
+	}
+	if by == "" {
+		by = api.RankByKarma
+	}
+
+	length, ok := periodLengths[period]
+	if !ok {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("unknown period %q", period))
+		return
+	}
+
+	if by != api.RankByKarma && by != api.RankByLevel && by != api.RankByCheersGiven {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("unknown ranking %q", by))
+		return
+	}
+
+	now := time.Now()
+	resp := api.Ranking{Period: period, By: by, To: now}
+
+	var previousFrom, previousTo time.Time
+	if length > 0 {
+		resp.From = now.Add(-length)
+		previousFrom, previousTo = resp.From.Add(-length), resp.From
+	} else {
+		previousTo = now.Add(-periodLengths[api.PeriodWeek])
+	}
+
+	s.store.view(func(f *fixtures) {
+		resp.Entries = rank(f, by, resp.From, resp.To)
+
+		previous := map[string]int{}
+		for _, e := range rank(f, by, previousFrom, previousTo) {
+			// members without karma or cheers given then weren't ranked
+			if score(by, e) > 0 {
+				previous[e.UserID] = e.Rank
+			}
+		}
+
+		for i := range resp.Entries {
+			resp.Entries[i].PreviousRank = previous[resp.Entries[i].UserID]
+		}
+	})
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// rank computes the standings of team members between two times, tied members sharing a rank
+func rank(f *fixtures, by string, from time.Time, to time.Time) []api.RankingEntry {
+	karma := map[string]int64{}
+	total := map[string]int64{}
+	given := map[string]int{}
+	totalGiven := map[string]int{}
+
+	for _, c := range f.Cheers {
+		if !c.CreatedAt.Before(to) {
+			continue
+		}
+
+		total[c.ToID] += int64(c.Karma)
+		totalGiven[c.FromID]++
+
+		if !c.CreatedAt.Before(from) {
+			karma[c.ToID] += int64(c.Karma)
+			given[c.FromID]++
+		}
+	}
+
+	entries := []api.RankingEntry{}
+	for _, u := range f.Users {
+		entries = append(entries, api.RankingEntry{
+			UserID:      u.ID,
+			Name:        u.Name,
+			Karma:       karma[u.ID],
+			Level:       levelStats(total[u.ID], totalGiven[u.ID]).Level,
+			CheersGiven: given[u.ID],
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return score(by, entries[i]) > score(by, entries[j]) })
+
+	for i := range entries {
+		if i > 0 && score(by, entries[i]) == score(by, entries[i-1]) {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries
+}
+
+func score(by string, e api.RankingEntry) int64 {
+	switch by {
+	case api.RankByLevel:
+		return int64(e.Level)
+	case api.RankByCheersGiven:
+		return int64(e.CheersGiven)
+	default:
+		return e.Karma
+	}
+}
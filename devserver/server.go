@@ -0,0 +1,356 @@
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/krmdv/cli/api"
+)
+
+// Server serves a stand-in for the Karma API backed by a fixture store
+type Server struct {
+	store   *Store
+	mux     *http.ServeMux
+	routes  map[string]map[string]http.HandlerFunc
+	devices deviceGrants
+
+	// FailureRate is the share of requests answered with a 503, to exercise retries
+	FailureRate float64
+}
+
+// NewServer returns a dev server handler for the given store
+func NewServer(store *Store) *Server {
+	s := &Server{store: store, mux: http.NewServeMux(), routes: map[string]map[string]http.HandlerFunc{}}
+
+	s.handlePublic("/auth/device", http.MethodPost, s.startDeviceLogin)
+	s.handlePublic("/auth/device/token", http.MethodPost, s.pollDeviceLogin)
+	s.mux.HandleFunc("/device", s.device)
+	s.handle("/auth/token", http.MethodDelete, s.revokeToken)
+	s.handlePublic("/meta", http.MethodGet, s.getMeta)
+	s.handle("/users/me", http.MethodGet, s.getMe)
+	s.handle("/users/me/setup", http.MethodPost, s.setupMe)
+	s.handle("/teams", http.MethodPost, s.setTeam)
+	s.handle("/teams/current", http.MethodGet, s.getTeam)
+	s.handle("/teams/current/slack-webhook-url", http.MethodPost, s.setSlackWebhookURL)
+	s.handle("/teams/current/invites", http.MethodPost, s.createInvite)
+	s.mux.HandleFunc("/teams/current/members/", s.authed(s.member))
+	s.handle("/feats", http.MethodGet, s.listFeats)
+	s.handle("/feats", http.MethodPost, s.createFeat)
+	s.handle("/feats/apply", http.MethodPost, s.applyFeats)
+	s.mux.HandleFunc("/feats/", s.authed(s.feat))
+	s.handle("/cheers", http.MethodPost, s.createCheer)
+	s.handle("/dashboard", http.MethodGet, s.getDashboard)
+	s.handle("/leaderboard", http.MethodGet, s.getRanking)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.FailureRate > 0 && rand.Float64() < s.FailureRate {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusServiceUnavailable, "simulated outage")
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle registers an authenticated handler for a single method
+func (s *Server) handle(path string, method string, h http.HandlerFunc) {
+	s.handlePublic(path, method, s.authed(h))
+}
+
+// handlePublic registers a handler for a single method, open to signed out users. Several
+// methods can be registered on the same path.
+func (s *Server) handlePublic(path string, method string, h http.HandlerFunc) {
+	if s.routes[path] == nil {
+		s.routes[path] = map[string]http.HandlerFunc{}
+
+		s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			h, ok := s.routes[path][r.Method]
+			if !ok {
+				writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed on %s", r.Method, path))
+				return
+			}
+
+			h(w, r)
+		})
+	}
+
+	s.routes[path][method] = h
+}
+
+// authed wraps a handler to reject requests without the API token
+func (s *Server) authed(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var token string
+		s.store.view(func(f *fixtures) { token = f.Token })
+
+		if r.Header.Get("Authorization") != "token "+token {
+			writeError(w, http.StatusUnauthorized, "invalid API token")
+			return
+		}
+
+		h(w, r)
+	}
+}
+
+func (s *Server) getMeta(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, api.Meta{APIVersion: "dev", MinCLIVersion: "v0.0.1", LatestCLIVersion: "v0.0.1"})
+}
+
+func (s *Server) getMe(w http.ResponseWriter, r *http.Request) {
+	var me api.User
+	s.store.view(func(f *fixtures) { me, _ = f.user(f.MeID) })
+
+	writeJSON(w, http.StatusOK, me)
+}
+
+func (s *Server) setupMe(w http.ResponseWriter, r *http.Request) {
+	if err := s.store.update(func(f *fixtures) error {
+		f.SetUp = true
+		return nil
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (s *Server) setTeam(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		GithubLogin string `json:"githubLogin"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.GithubLogin == "" {
+		writeError(w, http.StatusUnprocessableEntity, "githubLogin is required")
+		return
+	}
+
+	var resp api.Team
+
+	if err := s.store.update(func(f *fixtures) error {
+		f.Team.Name = payload.GithubLogin
+		resp = api.Team{ID: f.Team.ID, Token: f.Team.Token, Name: f.Team.Name}
+		resp.Users = append([]api.User{}, f.Users...)
+		return nil
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getTeam(w http.ResponseWriter, r *http.Request) {
+	var resp api.Team
+
+	s.store.view(func(f *fixtures) {
+		resp = api.Team{ID: f.Team.ID, Token: f.Team.Token, Name: f.Team.Name}
+		resp.Users = append([]api.User{}, f.Users...)
+	})
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) setSlackWebhookURL(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		SlackWebhookURL string `json:"slackWebhookUrl"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.update(func(f *fixtures) error {
+		f.Team.SlackWebhookURL = payload.SlackWebhookURL
+		return nil
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (s *Server) createCheer(w http.ResponseWriter, r *http.Request) {
+	var payload api.Cheer
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var resp api.CheerResult
+
+	key := r.Header.Get("Idempotency-Key")
+
+	err := s.store.update(func(f *fixtures) error {
+		if res, ok := f.Idempotency[key]; ok && key != "" {
+			resp = res
+			return nil
+		}
+
+		to, ok := f.user(payload.UserID)
+		if !ok {
+			return httpError{http.StatusNotFound, "no such user"}
+		}
+
+		if to.ID == f.MeID {
+			return httpError{http.StatusUnprocessableEntity, "you cannot cheer yourself"}
+		}
+
+		ft, ok := f.feat(payload.FeatID)
+		if !ok {
+			return httpError{http.StatusNotFound, "no such feat"}
+		}
+
+		if ft.Archived {
+			return httpError{http.StatusUnprocessableEntity, fmt.Sprintf("feat %s is archived", ft.Label)}
+		}
+
+		f.Cheers = append(f.Cheers, cheer{
+			ID:        fmt.Sprintf("c%d", len(f.Cheers)+1),
+			FromID:    f.MeID,
+			ToID:      to.ID,
+			FeatID:    ft.ID,
+			Message:   payload.Msg,
+			Karma:     ft.Karma,
+			CreatedAt: time.Now(),
+		})
+
+		resp = api.CheerResult{DeliveredToActiveUser: to.Active, Karma: ft.Karma}
+
+		if key != "" {
+			if f.Idempotency == nil {
+				f.Idempotency = map[string]api.CheerResult{}
+			}
+			f.Idempotency[key] = resp
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) getDashboard(w http.ResponseWriter, r *http.Request) {
+	var d api.Dashboard
+	s.store.view(func(f *fixtures) { d = buildDashboard(f) })
+
+	writeJSON(w, http.StatusOK, d)
+}
+
+// karmaPerLevel is the flat amount of karma needed to go up one level
+const karmaPerLevel = 100
+
+func levelStats(karma int64, given int) api.Stats {
+	if karma < 0 {
+		karma = 0
+	}
+
+	return api.Stats{
+		Level:            int(karma/karmaPerLevel) + 1,
+		Multiplier:       1 + given/10,
+		Progress:         int(karma % karmaPerLevel),
+		KarmaToNextLevel: karmaPerLevel - karma%karmaPerLevel,
+	}
+}
+
+func buildDashboard(f *fixtures) api.Dashboard {
+	var d api.Dashboard
+
+	received := map[string]int64{}
+	given := map[string]int{}
+	for _, c := range f.Cheers {
+		received[c.ToID] += int64(c.Karma)
+		given[c.FromID]++
+	}
+
+	d.User.ID = f.MeID
+	d.User.TotalAccruedKarma = received[f.MeID]
+	d.User.Stats = levelStats(received[f.MeID], given[f.MeID])
+
+	users := append([]api.User{}, f.Users...)
+	sort.SliceStable(users, func(i, j int) bool { return received[users[i].ID] > received[users[j].ID] })
+
+	for _, u := range users {
+		d.Leaderboard.Names = append(d.Leaderboard.Names, u.Name)
+		d.Leaderboard.Levels = append(d.Leaderboard.Levels, float64(levelStats(received[u.ID], given[u.ID]).Level))
+	}
+
+	for i := len(f.Cheers) - 1; i >= 0; i-- {
+		c := f.Cheers[i]
+		from, _ := f.user(c.FromID)
+		to, _ := f.user(c.ToID)
+
+		d.Logs = append(d.Logs, api.LogEntry{
+			ID:          c.ID,
+			Ago:         ago(time.Since(c.CreatedAt)),
+			From:        from.Name,
+			ToUser:      to.Name,
+			Karma:       c.Karma,
+			FeatID:      c.FeatID,
+			Message:     c.Message,
+			CurrentUser: c.ToID == f.MeID,
+		})
+	}
+
+	return d
+}
+
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// httpError lets store updates abort with a given status code
+type httpError struct {
+	status  int
+	message string
+}
+
+func (err httpError) Error() string {
+	return err.message
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	if httpErr, ok := err.(httpError); ok {
+		writeError(w, httpErr.status, httpErr.message)
+		return
+	}
+
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, struct {
+		Message string `json:"message"`
+	}{strings.TrimSpace(message)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
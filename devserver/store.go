@@ -0,0 +1,199 @@
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/krmdv/cli/api"
+)
+
+type cheer struct {
+	ID        string    `json:"id"`
+	FromID    string    `json:"fromUserId"`
+	ToID      string    `json:"toUserId"`
+	FeatID    string    `json:"featId"`
+	Message   string    `json:"message"`
+	Karma     int       `json:"karma"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type invite struct {
+	Code      string    `json:"code"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type team struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Token           string `json:"apiToken"`
+	SlackWebhookURL string `json:"slackWebhookUrl"`
+}
+
+// fixtures is the on-disk state of the dev server
+type fixtures struct {
+	Token   string     `json:"token"`
+	MeID    string     `json:"meId"`
+	Team    team       `json:"team"`
+	Users   []api.User `json:"users"`
+	Feats   []api.Feat `json:"feats"`
+	Cheers  []cheer    `json:"cheers"`
+	SetUp   bool       `json:"setUp"`
+	Invites []invite   `json:"invites,omitempty"`
+
+	// Idempotency holds the results of cheers by idempotency key, so retries are not awarded twice
+	Idempotency map[string]api.CheerResult `json:"idempotency,omitempty"`
+}
+
+// Store keeps the dev server fixtures in memory and persists every change to disk
+type Store struct {
+	mu   sync.Mutex
+	path string
+	data fixtures
+}
+
+// OpenStore loads fixtures from path, seeding the file if it does not exist yet
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		s.data = seed()
+		return s, s.save()
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &s.data); err != nil {
+		return nil, err
+	}
+
+	// fixtures seeded before roles existed have no admin, make the signed in user one
+	hasAdmin := s.data.hasAdmin()
+	for i, u := range s.data.Users {
+		switch {
+		case u.ID == s.data.MeID && !hasAdmin:
+			s.data.Users[i].Role = api.RoleAdmin
+		case u.Role == "":
+			s.data.Users[i].Role = api.RoleMember
+		}
+	}
+
+	return s, nil
+}
+
+// update runs fn while holding the store lock and persists the result
+func (s *Store) update(fn func(f *fixtures) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := fn(&s.data); err != nil {
+		return err
+	}
+
+	return s.save()
+}
+
+// view runs fn while holding the store lock
+func (s *Store) view(fn func(f *fixtures)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(&s.data)
+}
+
+func (s *Store) save() error {
+	content, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(s.path, content, 0600)
+}
+
+func (f *fixtures) user(id string) (api.User, bool) {
+	for _, u := range f.Users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+
+	return api.User{}, false
+}
+
+func (f *fixtures) hasAdmin() bool {
+	for _, u := range f.Users {
+		if u.Role == api.RoleAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkAdmin fails unless the signed in user is a team admin
+func (f *fixtures) checkAdmin(action string) error {
+	if me, _ := f.user(f.MeID); me.Role != api.RoleAdmin {
+		return httpError{http.StatusForbidden, fmt.Sprintf("only team admins can %s", action)}
+	}
+
+	return nil
+}
+
+func (f *fixtures) userIndex(id string) int {
+	for i, u := range f.Users {
+		if u.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (f *fixtures) feat(id string) (api.Feat, bool) {
+	for _, ft := range f.Feats {
+		if ft.ID == id {
+			return ft, true
+		}
+	}
+
+	return api.Feat{}, false
+}
+
+func seed() fixtures {
+	now := time.Now()
+
+	return fixtures{
+		Token: "dev-token",
+		MeID:  "u1",
+		Team: team{
+			ID:    "t1",
+			Name:  "karma-dev",
+			Token: "dev-team-token",
+		},
+		Users: []api.User{
+			{ID: "u1", Name: "you", Login: "you", Email: "you@example.com", Active: true, Role: api.RoleAdmin},
+			{ID: "u2", Name: "Alice Martin", Login: "alice", Email: "alice@example.com", Active: true, Role: api.RoleMember},
+			{ID: "u3", Name: "Bob Smith", Login: "bobsmith", Email: "bob@example.com", Aliases: []string{"bobby"}, Active: true, Role: api.RoleMember},
+			{ID: "u4", Name: "Carol Jones", Login: "cjones", Email: "carol@example.com", Active: false, Role: api.RoleMember},
+		},
+		Feats: []api.Feat{
+			{ID: "f1", Label: "Pairing", Slug: "pairing", Karma: 50},
+			{ID: "f2", Label: "Code review", Slug: "review", Karma: 30},
+			{ID: "f3", Label: "React Guru", Slug: "react", Karma: 100},
+			{ID: "f4", Label: "Super Hacker", Slug: "hacker", Karma: 200},
+			{ID: "f5", Label: "Broke the build", Slug: "broken-build", Karma: -20},
+		},
+		Cheers: []cheer{
+			{ID: "c1", FromID: "u2", ToID: "u1", FeatID: "f1", Karma: 50, Message: "Great session!", CreatedAt: now.Add(-3 * time.Hour)},
+			{ID: "c2", FromID: "u1", ToID: "u3", FeatID: "f2", Karma: 30, Message: "Thanks for the thorough review", CreatedAt: now.Add(-2 * time.Hour)},
+			{ID: "c3", FromID: "u3", ToID: "u2", FeatID: "f3", Karma: 100, CreatedAt: now.Add(-30 * time.Minute)},
+		},
+	}
+}
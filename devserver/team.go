@@ -0,0 +1,173 @@
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/krmdv/cli/api"
+)
+
+const inviteExpiry = 7 * 24 * time.Hour
+
+func (s *Server) createInvite(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if payload.Role == "" {
+		payload.Role = api.RoleMember
+	}
+
+	var resp api.Invite
+
+	err := s.store.update(func(f *fixtures) error {
+		if err := validateRole(payload.Role); err != nil {
+			return err
+		}
+
+		if payload.Role == api.RoleAdmin {
+			if err := f.checkAdmin("invite admins"); err != nil {
+				return err
+			}
+		}
+
+		inv := invite{Code: randomHex(8), Email: payload.Email, Role: payload.Role, ExpiresAt: time.Now().Add(inviteExpiry)}
+		f.Invites = append(f.Invites, inv)
+
+		resp = api.Invite{
+			URL:       fmt.Sprintf("http://%s/join/%s", r.Host, inv.Code),
+			Email:     inv.Email,
+			Role:      inv.Role,
+			ExpiresAt: inv.ExpiresAt,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// member serves /teams/current/members/<id>
+func (s *Server) member(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/teams/current/members/")
+
+	switch r.Method {
+	case http.MethodPatch:
+		s.setMemberRole(w, r, id)
+	case http.MethodDelete:
+		s.removeMember(w, r, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed on %s", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) setMemberRole(w http.ResponseWriter, r *http.Request, id string) {
+	var payload struct {
+		Role string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var resp api.User
+
+	err := s.store.update(func(f *fixtures) error {
+		if err := f.checkAdmin("change roles"); err != nil {
+			return err
+		}
+
+		if err := validateRole(payload.Role); err != nil {
+			return err
+		}
+
+		i := f.userIndex(id)
+		if i < 0 {
+			return httpError{http.StatusNotFound, "no such team member"}
+		}
+
+		if payload.Role != api.RoleAdmin {
+			if err := f.checkNotLastAdmin(id); err != nil {
+				return err
+			}
+		}
+
+		f.Users[i].Role = payload.Role
+		resp = f.Users[i]
+
+		return nil
+	})
+
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) removeMember(w http.ResponseWriter, r *http.Request, id string) {
+	err := s.store.update(func(f *fixtures) error {
+		if err := f.checkAdmin("remove members"); err != nil {
+			return err
+		}
+
+		i := f.userIndex(id)
+		if i < 0 {
+			return httpError{http.StatusNotFound, "no such team member"}
+		}
+
+		if err := f.checkNotLastAdmin(id); err != nil {
+			return err
+		}
+
+		f.Users = append(f.Users[:i], f.Users[i+1:]...)
+
+		return nil
+	})
+
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkNotLastAdmin fails if a member is the only admin left, so the team can still be managed
+func (f *fixtures) checkNotLastAdmin(id string) error {
+	for _, u := range f.Users {
+		if u.Role == api.RoleAdmin && u.ID != id {
+			return nil
+		}
+	}
+
+	if u, ok := f.user(id); ok && u.Role == api.RoleAdmin {
+		return httpError{http.StatusUnprocessableEntity, fmt.Sprintf("%s is the last admin of the team, make someone else admin first", u.Name)}
+	}
+
+	return nil
+}
+
+func validateRole(role string) error {
+	if role != api.RoleAdmin && role != api.RoleMember {
+		return httpError{http.StatusUnprocessableEntity, fmt.Sprintf("role must be %s or %s", api.RoleAdmin, api.RoleMember)}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package feats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	slugRE    = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	nonSlugRE = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+func addFeatFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("label", "l", "", "what developers are cheered for, e.g. \"Code review\"")
+	cmd.Flags().StringP("slug", "s", "", "short name to cheer with, e.g. review")
+	cmd.Flags().IntP("karma", "k", 0, "karma awarded by each cheer, negative for feats nobody wants")
+}
+
+func createRun(ctx context.Context, client api.Client, conf config.Configuration, flags *pflag.FlagSet) error {
+	format, err := output.FromFlags(flags)
+	if err != nil {
+		return err
+	}
+
+	label, _ := flags.GetString("label")
+	slug, _ := flags.GetString("slug")
+	karma, _ := flags.GetInt("karma")
+
+	interactive := isatty.IsTerminal(os.Stdin.Fd())
+
+	if label == "" {
+		if !interactive {
+			return errors.New("--label is required when not running interactively")
+		}
+
+		err := survey.AskOne(&survey.Input{
+			Message: "What are developers cheered for?",
+		}, &label, survey.WithValidator(survey.Required))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if slug == "" {
+		slug = slugify(label)
+	}
+
+	if !flags.Changed("karma") {
+		if !interactive {
+			return errors.New("--karma is required when not running interactively")
+		}
+
+		var answer string
+		err := survey.AskOne(&survey.Input{
+			Message: "How much karma does each cheer award?",
+		}, &answer, survey.WithValidator(validateKarma))
+
+		if err != nil {
+			return err
+		}
+
+		karma, _ = strconv.Atoi(strings.TrimSpace(answer))
+	}
+
+	if err := validateFeat(api.Feat{Label: label, Slug: slug, Karma: karma}); err != nil {
+		return err
+	}
+
+	feat, err := client.CreateFeat(ctx, api.Feat{Label: label, Slug: slug, Karma: karma})
+	if err != nil {
+		return err
+	}
+
+	cacheFeats(append(conf.Feats, feat))
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, feat)
+	}
+
+	color.Green(fmt.Sprintf("✅ Created %s, cheer for it with 'karma c <developer> -f %s' (%+d pts).", feat.Label, feat.Slug, feat.Karma))
+
+	return nil
+}
+
+func editRun(ctx context.Context, client api.Client, conf config.Configuration, query string, flags *pflag.FlagSet) error {
+	format, err := output.FromFlags(flags)
+	if err != nil {
+		return err
+	}
+
+	var changes api.FeatChanges
+
+	if flags.Changed("label") {
+		label, _ := flags.GetString("label")
+		changes.Label = &label
+	}
+	if flags.Changed("slug") {
+		slug, _ := flags.GetString("slug")
+		changes.Slug = &slug
+	}
+	if flags.Changed("karma") {
+		karma, _ := flags.GetInt("karma")
+		changes.Karma = &karma
+	}
+
+	if changes == (api.FeatChanges{}) {
+		return errors.New("nothing to change, pass --label, --slug or --karma")
+	}
+
+	feat, err := findFeat(ctx, client, query)
+	if err != nil {
+		return err
+	}
+
+	edited := feat
+	if changes.Label != nil {
+		edited.Label = *changes.Label
+	}
+	if changes.Slug != nil {
+		edited.Slug = *changes.Slug
+	}
+	if changes.Karma != nil {
+		edited.Karma = *changes.Karma
+	}
+
+	if err := validateFeat(edited); err != nil {
+		return err
+	}
+
+	updated, err := client.UpdateFeat(ctx, feat.ID, changes)
+	if err != nil {
+		return err
+	}
+
+	feats := append([]api.Feat{}, conf.Feats...)
+	for i, f := range feats {
+		if f.ID == updated.ID {
+			feats[i] = updated
+		}
+	}
+
+	cacheFeats(feats)
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, updated)
+	}
+
+	color.Green(fmt.Sprintf("✅ Updated %s: %s.", feat.Label, strings.Join(describeChanges(feat, updated), ", ")))
+
+	return nil
+}
+
+// describeChanges lists the fields that differ between two versions of a feat
+func describeChanges(before api.Feat, after api.Feat) []string {
+	var changes []string
+
+	if before.Label != after.Label {
+		changes = append(changes, fmt.Sprintf("label %q → %q", before.Label, after.Label))
+	}
+	if before.Slug != after.Slug {
+		changes = append(changes, fmt.Sprintf("slug %s → %s", before.Slug, after.Slug))
+	}
+	if before.Karma != after.Karma {
+		changes = append(changes, fmt.Sprintf("karma %+d → %+d", before.Karma, after.Karma))
+	}
+
+	if len(changes) == 0 {
+		changes = append(changes, "nothing changed")
+	}
+
+	return changes
+}
+
+// slugify turns a label into a slug, e.g. "Code review" into "code-review"
+func slugify(label string) string {
+	return strings.Trim(nonSlugRE.ReplaceAllString(strings.ToLower(label), "-"), "-")
+}
+
+// validateFeat checks a feat before sending it to the API, for clearer errors
+func validateFeat(feat api.Feat) error {
+	switch {
+	case strings.TrimSpace(feat.Label) == "":
+		return errors.New("a feat needs a label")
+	case !slugRE.MatchString(feat.Slug):
+		return fmt.Errorf("slug %q should be lowercase letters, digits and dashes", feat.Slug)
+	case feat.Karma == 0:
+		return fmt.Errorf("feat %s should award some karma, or be archived", feat.Label)
+	}
+
+	return nil
+}
+
+func validateKarma(answer interface{}) error {
+	karma, err := strconv.Atoi(strings.TrimSpace(answer.(string)))
+	if err != nil || karma == 0 {
+		return errors.New("karma should be a whole number other than 0, e.g. 30 or -20")
+	}
+
+	return nil
+}
@@ -0,0 +1,415 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feats
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	cheerCmd "github.com/krmdv/cli/cheer"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdFeats creates a feats command
+func NewCmdFeats(client *api.Client, conf *config.Configuration) *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "feats",
+		Short: "Browse and manage the feats of your team",
+		Long: heredoc.Doc(`
+			Browse the feats developers of your team can be cheered for, with the karma
+			they award and how many times they were cheered.
+
+			Team admins can also create, edit and archive feats. Archived feats can't be
+			cheered anymore, past cheers keep their karma. Feats are designated by slug
+			or label, like when cheering.
+		`),
+		Example: heredoc.Doc(`
+			$ karma feats
+			$ karma feats show review
+
+			# admins only
+			$ karma feats create --label "Fixed a flaky test" --karma 40
+			$ karma feats edit flaky --karma 60
+			$ karma feats archive flaky
+
+			# keep feats in a reviewed file, and apply it once merged
+			$ karma feats list --output yaml > feats.yaml
+			$ karma feats plan -f feats.yaml
+			$ karma feats apply -f feats.yaml --prune
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runList(cmd, client, conf)
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List feats",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runList(cmd, client, conf)
+		},
+	}
+	for _, c := range []*cobra.Command{cmd, listCmd} {
+		c.Flags().Bool("archived", false, "include archived feats")
+	}
+	cmd.AddCommand(listCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:               "show <feat>",
+		Short:             "Show a feat",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFeat,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return showRun(cmd.Context(), *client, format, args[0])
+		},
+	})
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a feat, admins only",
+		Long: heredoc.Doc(`
+			Create a feat. Missing values are prompted for, and the slug defaults to the
+			label in lowercase with dashes.
+
+			Karma can be negative, for feats nobody wants to be cheered for.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			return output.HandleInterrupt(createRun(cmd.Context(), *client, *conf, cmd.Flags()))
+		},
+	}
+	addFeatFlags(createCmd)
+	cmd.AddCommand(createCmd)
+
+	editCmd := &cobra.Command{
+		Use:               "edit <feat>",
+		Short:             "Change the label, slug or karma of a feat, admins only",
+		Long:              "Change the label, slug or karma of a feat. Past cheers keep the karma they awarded.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFeat,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			return editRun(cmd.Context(), *client, *conf, args[0], cmd.Flags())
+		},
+	}
+	addFeatFlags(editCmd)
+	cmd.AddCommand(editCmd)
+
+	archiveCmd := &cobra.Command{
+		Use:               "archive <feat>",
+		Short:             "Stop a feat from being cheered, admins only",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFeat,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			yes, _ := cmd.Flags().GetBool("yes")
+
+			return output.HandleInterrupt(archiveRun(cmd.Context(), *client, *conf, format, args[0], yes))
+		},
+	}
+	archiveCmd.Flags().BoolP("yes", "y", false, "archive without asking for confirmation")
+	cmd.AddCommand(archiveCmd)
+
+	planCmd := &cobra.Command{
+		Use:   "plan -f <file>",
+		Short: "Show how a feats file differs from the feats of your team",
+		Long: heredoc.Doc(`
+			Show the changes applying a feats file would make, without making them.
+
+			The file is YAML or JSON, listing feats by slug with their label and karma,
+			like the output of 'karma feats list --output yaml'. It can also be a document with
+			the list under a feats key. Slugs default to the label in lowercase with dashes.
+
+			Feats missing from the file are kept, unless --prune is given to archive them.
+			Archived feats listed in the file are restored.
+		`),
+		Example: heredoc.Doc(`
+			# feats.yaml
+			- slug: review
+			  label: Code review
+			  karma: 30
+			- label: Pairing
+			  karma: 50
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			file, _ := cmd.Flags().GetString("file")
+			prune, _ := cmd.Flags().GetBool("prune")
+
+			return planRun(cmd.Context(), *client, format, file, prune)
+		},
+	}
+	cmd.AddCommand(planCmd)
+
+	applyCmd := &cobra.Command{
+		Use:   "apply -f <file>",
+		Short: "Make the feats of your team match a feats file, admins only",
+		Long: heredoc.Doc(`
+			Make the feats of your team match a feats file, see 'karma feats plan'.
+
+			The plan is shown and confirmed first, unless --yes is given. All the changes
+			are applied at once: if any of them is rejected, none is made.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			file, _ := cmd.Flags().GetString("file")
+			prune, _ := cmd.Flags().GetBool("prune")
+			yes, _ := cmd.Flags().GetBool("yes")
+
+			return output.HandleInterrupt(applyRun(cmd.Context(), *client, format, file, prune, yes))
+		},
+	}
+	applyCmd.Flags().BoolP("yes", "y", false, "apply without asking for confirmation")
+	cmd.AddCommand(applyCmd)
+
+	for _, c := range []*cobra.Command{planCmd, applyCmd} {
+		c.Flags().StringP("file", "f", "", "YAML or JSON feats file, - for stdin")
+		c.Flags().Bool("prune", false, "archive feats missing from the file")
+		c.MarkFlagRequired("file")
+		c.MarkFlagFilename("file", "yaml", "yml", "json")
+	}
+
+	return cmd
+}
+
+func runList(cmd *cobra.Command, client *api.Client, conf *config.Configuration) error {
+	if err := config.CheckLoaded(); err != nil {
+		return err
+	}
+
+	format, err := output.FromFlags(cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	archived, _ := cmd.Flags().GetBool("archived")
+
+	return listRun(cmd.Context(), *client, format, archived)
+}
+
+func listRun(ctx context.Context, client api.Client, format output.Format, archived bool) error {
+	feats, err := client.ListAllFeats(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the API's active feats are the freshest the cache can get
+	active := []api.Feat{}
+	for _, f := range feats {
+		if !f.Archived {
+			active = append(active, f)
+		}
+	}
+	cacheFeats(active)
+
+	if !archived {
+		feats = active
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, feats)
+	}
+
+	if len(feats) == 0 {
+		fmt.Println("No feats yet, team admins can create some with 'karma feats create'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SLUG\tLABEL\tKARMA\tCHEERS\t")
+	for _, f := range feats {
+		status := ""
+		if f.Archived {
+			status = "archived"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%+d\t%d\t%s\n", f.Slug, f.Label, f.Karma, f.Uses, status)
+	}
+
+	return w.Flush()
+}
+
+func showRun(ctx context.Context, client api.Client, format output.Format, query string) error {
+	feat, err := findFeat(ctx, client, query)
+	if err != nil {
+		return err
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, feat)
+	}
+
+	status := "active"
+	if feat.Archived {
+		status = "archived, can't be cheered anymore"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Label:\t%s\n", feat.Label)
+	fmt.Fprintf(w, "Slug:\t%s\n", feat.Slug)
+	fmt.Fprintf(w, "Karma:\t%+d pts\n", feat.Karma)
+	fmt.Fprintf(w, "Cheers:\t%d\n", feat.Uses)
+	fmt.Fprintf(w, "Status:\t%s\n", status)
+	fmt.Fprintf(w, "ID:\t%s\n", feat.ID)
+
+	return w.Flush()
+}
+
+func archiveRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format, query string, yes bool) error {
+	feat, err := findFeat(ctx, client, query)
+	if err != nil {
+		return err
+	}
+
+	if feat.Archived {
+		if format.Structured() {
+			return output.Print(os.Stdout, format, feat)
+		}
+
+		color.Yellow(fmt.Sprintf("Feat %s is already archived.", feat.Label))
+		return nil
+	}
+
+	if !yes {
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("pass --yes to archive %s without a prompt", feat.Label)
+		}
+
+		err := survey.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Archive %s? It was cheered %d times and can't be cheered anymore once archived.", feat.Label, feat.Uses),
+		}, &yes)
+
+		if err != nil || !yes {
+			return err
+		}
+	}
+
+	archived, err := client.ArchiveFeat(ctx, feat.ID)
+	if err != nil {
+		return err
+	}
+
+	var feats []api.Feat
+	for _, f := range conf.Feats {
+		if f.ID != feat.ID {
+			feats = append(feats, f)
+		}
+	}
+
+	cacheFeats(feats)
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, archived)
+	}
+
+	color.Green(fmt.Sprintf("✅ Archived %s, past cheers keep their karma.", feat.Label))
+
+	return nil
+}
+
+// cacheFeats saves feats for cheers and completions. The API already has them, so failing to write
+// the cache only calls for a warning.
+func cacheFeats(feats []api.Feat) {
+	if err := config.SaveFeats(feats); err != nil {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "Heads up! Couldn't update your cached feats, run 'karma sync' to retry: %v\n", err)
+	}
+}
+
+// findFeat gets a feat from the API by slug or label, archived ones included
+func findFeat(ctx context.Context, client api.Client, query string) (api.Feat, error) {
+	feats, err := client.ListAllFeats(ctx)
+	if err != nil {
+		return api.Feat{}, err
+	}
+
+	return cheerCmd.FindFeat(feats, query)
+}
+
+// completeFeat suggests the slugs of cached feats for the first argument
+func completeFeat(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	conf, ok := cheerCmd.CachedConfig(cmd)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, f := range conf.Feats {
+		completions = append(completions, fmt.Sprintf("%s\t%s (%+d pts)", f.Slug, f.Label, f.Karma))
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
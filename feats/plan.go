@@ -0,0 +1,356 @@
+package feats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/mattn/go-isatty"
+	"gopkg.in/yaml.v2"
+)
+
+// featSpec is a feat as declared in a feats file
+type featSpec struct {
+	Slug  string `yaml:"slug"`
+	Label string `yaml:"label"`
+	Karma int    `yaml:"karma"`
+
+	// the other fields of 'karma feats list --output yaml' are ignored, so its output can be used as is
+	ID       string `yaml:"id"`
+	Uses     int    `yaml:"uses"`
+	Archived bool   `yaml:"archived"`
+}
+
+// featsFile is the content of a feats file, either this document or just the list of feats
+type featsFile struct {
+	Feats []featSpec `yaml:"feats"`
+}
+
+// change is a difference between the feats of a file and the API's
+type change struct {
+	Action string    `json:"action"`
+	Slug   string    `json:"slug"`
+	Before *api.Feat `json:"before,omitempty"`
+	After  *api.Feat `json:"after,omitempty"`
+}
+
+const (
+	actionCreate  = "create"
+	actionUpdate  = "update"
+	actionArchive = "archive"
+)
+
+// planOutput is the structured description of a plan
+type planOutput struct {
+	Changes []change `json:"changes"`
+	// Kept are the slugs of feats missing from the file, archived only with --prune
+	Kept    []string `json:"kept"`
+	Applied bool     `json:"applied"`
+}
+
+// readFeatsFile parses a YAML or JSON feats file, or stdin if path is "-"
+func readFeatsFile(path string) ([]featSpec, error) {
+	name := "stdin"
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		name = path
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, so a single parser handles both
+	var doc interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", name, err)
+	}
+
+	var specs []featSpec
+	if _, ok := doc.([]interface{}); ok {
+		err = yaml.UnmarshalStrict(content, &specs)
+	} else {
+		var file featsFile
+		err = yaml.UnmarshalStrict(content, &file)
+		specs = file.Feats
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", name, err)
+	}
+
+	// an empty file would archive every feat with --prune, it's more likely a mistake
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("%s declares no feats", name)
+	}
+
+	seen := map[string]bool{}
+	for i, spec := range specs {
+		if spec.Slug == "" {
+			spec.Slug = slugify(spec.Label)
+			specs[i] = spec
+		}
+
+		if err := validateFeat(api.Feat{Label: spec.Label, Slug: spec.Slug, Karma: spec.Karma}); err != nil {
+			return nil, fmt.Errorf("%s: feat #%d: %w", name, i+1, err)
+		}
+
+		if seen[spec.Slug] {
+			return nil, fmt.Errorf("%s: slug %s is declared twice", name, spec.Slug)
+		}
+		seen[spec.Slug] = true
+	}
+
+	return specs, nil
+}
+
+// diff compares declared feats with the API's, by slug. Archived feats declared again are restored,
+// and active feats missing from the file are archived with prune, kept otherwise.
+func diff(specs []featSpec, feats []api.Feat, prune bool) (changes []change, kept []string) {
+	bySlug := map[string]api.Feat{}
+	for _, f := range feats {
+		bySlug[f.Slug] = f
+	}
+
+	declared := map[string]bool{}
+	for _, spec := range specs {
+		declared[spec.Slug] = true
+
+		after := api.Feat{Slug: spec.Slug, Label: spec.Label, Karma: spec.Karma}
+
+		before, ok := bySlug[spec.Slug]
+		if !ok {
+			changes = append(changes, change{Action: actionCreate, Slug: spec.Slug, After: &after})
+			continue
+		}
+
+		after.ID, after.Uses = before.ID, before.Uses
+		if before.Label != after.Label || before.Karma != after.Karma || before.Archived {
+			b := before
+			changes = append(changes, change{Action: actionUpdate, Slug: spec.Slug, Before: &b, After: &after})
+		}
+	}
+
+	for _, f := range feats {
+		if declared[f.Slug] || f.Archived {
+			continue
+		}
+
+		if !prune {
+			kept = append(kept, f.Slug)
+			continue
+		}
+
+		b := f
+		changes = append(changes, change{Action: actionArchive, Slug: f.Slug, Before: &b})
+	}
+
+	return changes, kept
+}
+
+// toPlan turns changes into the request applying them
+func toPlan(changes []change) api.FeatPlan {
+	var plan api.FeatPlan
+
+	for _, c := range changes {
+		switch c.Action {
+		case actionCreate:
+			plan.Create = append(plan.Create, *c.After)
+		case actionArchive:
+			plan.Archive = append(plan.Archive, c.Before.ID)
+		case actionUpdate:
+			u := api.FeatUpdate{ID: c.Before.ID}
+			if c.Before.Label != c.After.Label {
+				u.Label = &c.After.Label
+			}
+			if c.Before.Karma != c.After.Karma {
+				u.Karma = &c.After.Karma
+			}
+			if c.Before.Archived {
+				restored := false
+				u.Archived = &restored
+			}
+			plan.Update = append(plan.Update, u)
+		}
+	}
+
+	return plan
+}
+
+func planRun(ctx context.Context, client api.Client, format output.Format, path string, prune bool) error {
+	changes, kept, err := computePlan(ctx, client, path, prune)
+	if err != nil {
+		return err
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, planOutput{Changes: changes, Kept: kept})
+	}
+
+	printPlan(changes, kept)
+
+	if len(changes) > 0 {
+		fmt.Printf("\nRun 'karma feats apply -f %s%s' to apply these changes.\n", path, pruneFlag(prune))
+	}
+
+	return nil
+}
+
+func applyRun(ctx context.Context, client api.Client, format output.Format, path string, prune bool, yes bool) error {
+	if path == "-" && !yes {
+		return errors.New("pass --yes to apply feats read from stdin, as it can't be confirmed")
+	}
+
+	changes, kept, err := computePlan(ctx, client, path, prune)
+	if err != nil {
+		return err
+	}
+
+	if !format.Structured() {
+		printPlan(changes, kept)
+	}
+
+	if len(changes) == 0 {
+		if format.Structured() {
+			return output.Print(os.Stdout, format, planOutput{Changes: changes, Kept: kept})
+		}
+		return nil
+	}
+
+	if !yes {
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return errors.New("pass --yes to apply these changes without a prompt")
+		}
+
+		fmt.Println()
+		err := survey.AskOne(&survey.Confirm{Message: "Apply these changes?"}, &yes)
+		if err != nil || !yes {
+			return err
+		}
+	}
+
+	feats, err := client.ApplyFeats(ctx, toPlan(changes))
+	if err != nil {
+		return fmt.Errorf("no change was applied: %w", err)
+	}
+
+	if config.CheckLoaded() == nil {
+		cacheFeats(feats)
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, planOutput{Changes: changes, Kept: kept, Applied: true})
+	}
+
+	created, updated, archived := count(changes)
+	color.Green(fmt.Sprintf("✅ Created %d, updated %d and archived %d feats.", created, updated, archived))
+
+	return nil
+}
+
+func computePlan(ctx context.Context, client api.Client, path string, prune bool) ([]change, []string, error) {
+	specs, err := readFeatsFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	feats, err := client.ListAllFeats(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes, kept := diff(specs, feats, prune)
+	if changes == nil {
+		changes = []change{}
+	}
+	if kept == nil {
+		kept = []string{}
+	}
+
+	return changes, kept, nil
+}
+
+func printPlan(changes []change, kept []string) {
+	if len(changes) == 0 {
+		fmt.Println("No changes, the feats of your team match the file.")
+	}
+
+	green := color.New(color.FgGreen)
+	yellow := color.New(color.FgYellow)
+	red := color.New(color.FgRed)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, c := range changes {
+		switch c.Action {
+		case actionCreate:
+			fmt.Fprintf(w, "%s\t%s\t%s\t%+d pts\n", green.Sprint("+"), c.Slug, c.After.Label, c.After.Karma)
+		case actionUpdate:
+			var details []string
+			if c.Before.Archived {
+				details = append(details, "restored")
+			}
+			if c.Before.Label != c.After.Label || c.Before.Karma != c.After.Karma {
+				details = append(details, describeChanges(*c.Before, *c.After)...)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", yellow.Sprint("~"), c.Slug, strings.Join(details, ", "))
+		case actionArchive:
+			fmt.Fprintf(w, "%s\t%s\t%s\tarchived, cheered %d times\n", red.Sprint("-"), c.Slug, c.Before.Label, c.Before.Uses)
+		}
+	}
+	w.Flush()
+
+	if len(kept) > 0 {
+		color.Yellow(fmt.Sprintf("\n%s not in the file but kept, pass --prune to archive them.", strings.Join(kept, ", ")))
+	}
+
+	if len(changes) > 0 {
+		fmt.Printf("\nPlan: %s.\n", summary(changes))
+	}
+}
+
+// summary counts changes by action, e.g. "1 to create, 2 to update, 0 to archive"
+func summary(changes []change) string {
+	created, updated, archived := count(changes)
+
+	return fmt.Sprintf("%d to create, %d to update, %d to archive", created, updated, archived)
+}
+
+func count(changes []change) (created int, updated int, archived int) {
+	for _, c := range changes {
+		switch c.Action {
+		case actionCreate:
+			created++
+		case actionUpdate:
+			updated++
+		case actionArchive:
+			archived++
+		}
+	}
+
+	return created, updated, archived
+}
+
+func pruneFlag(prune bool) string {
+	if prune {
+		return " --prune"
+	}
+
+	return ""
+}
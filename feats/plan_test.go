@@ -0,0 +1,145 @@
+package feats
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/krmdv/cli/api"
+)
+
+func TestReadFeatsFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []featSpec
+		err     string
+	}{
+		{
+			name:    "document",
+			content: "feats:\n  - slug: pairing\n    label: Pairing\n    karma: 50\n",
+			want:    []featSpec{{Slug: "pairing", Label: "Pairing", Karma: 50}},
+		},
+		{
+			name:    "list after a comment",
+			content: "# our feats\n- label: Code Review\n  karma: 30\n",
+			want:    []featSpec{{Slug: "code-review", Label: "Code Review", Karma: 30}},
+		},
+		{
+			name:    "json",
+			content: `[{"id": "f1", "slug": "pairing", "label": "Pairing", "karma": 50, "uses": 3}]`,
+			want:    []featSpec{{Slug: "pairing", Label: "Pairing", Karma: 50, ID: "f1", Uses: 3}},
+		},
+		{name: "empty", content: "", err: "declares no feats"},
+		{name: "empty document", content: "feats: []\n", err: "declares no feats"},
+		{name: "unknown field", content: "- label: Pairing\n  karma: 50\n  points: 2\n", err: "could not parse"},
+		{name: "no karma", content: "- label: Pairing\n", err: "feat #1"},
+		{
+			name:    "duplicate",
+			content: "- label: Pairing\n  karma: 50\n- slug: pairing\n  label: Pair programming\n  karma: 40\n",
+			err:     "declared twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "feats.yml")
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readFeatsFile(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("readFeatsFile() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readFeatsFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	feats := []api.Feat{
+		{ID: "f1", Slug: "pairing", Label: "Pairing", Karma: 50, Uses: 4},
+		{ID: "f2", Slug: "review", Label: "Review", Karma: 30},
+		{ID: "f3", Slug: "hacker", Label: "Hacker", Karma: 200},
+		{ID: "f4", Slug: "legacy", Label: "Legacy", Karma: 10, Archived: true},
+	}
+
+	specs := []featSpec{
+		{Slug: "pairing", Label: "Pairing", Karma: 50},
+		{Slug: "review", Label: "Code review", Karma: 40},
+		{Slug: "legacy", Label: "Legacy", Karma: 10},
+		{Slug: "docs", Label: "Docs", Karma: 20},
+	}
+
+	tests := []struct {
+		name    string
+		prune   bool
+		actions []string
+		kept    []string
+	}{
+		{
+			name:    "keep",
+			actions: []string{"update review", "update legacy", "create docs"},
+			kept:    []string{"hacker"},
+		},
+		{
+			name:    "prune",
+			prune:   true,
+			actions: []string{"update review", "update legacy", "create docs", "archive hacker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, kept := diff(specs, feats, tt.prune)
+
+			var actions []string
+			for _, c := range changes {
+				actions = append(actions, c.Action+" "+c.Slug)
+			}
+
+			if !reflect.DeepEqual(actions, tt.actions) {
+				t.Errorf("diff() changes = %v, want %v", actions, tt.actions)
+			}
+			if !reflect.DeepEqual(kept, tt.kept) {
+				t.Errorf("diff() kept = %v, want %v", kept, tt.kept)
+			}
+		})
+	}
+
+	changes, _ := diff(specs, feats, false)
+	plan := toPlan(changes)
+
+	if len(plan.Create) != 1 || plan.Create[0].Slug != "docs" {
+		t.Errorf("plan creates %+v, want docs", plan.Create)
+	}
+
+	review, legacy := plan.Update[0], plan.Update[1]
+	if review.ID != "f2" || review.Label == nil || *review.Label != "Code review" || *review.Karma != 40 || review.Archived != nil {
+		t.Errorf("review update = %+v", review)
+	}
+	if legacy.ID != "f4" || legacy.Label != nil || legacy.Karma != nil || legacy.Archived == nil || *legacy.Archived {
+		t.Errorf("legacy update = %+v, want it restored only", legacy)
+	}
+}
+
+func TestDiffUpToDate(t *testing.T) {
+	feats := []api.Feat{{ID: "f1", Slug: "pairing", Label: "Pairing", Karma: 50}}
+	specs := []featSpec{{Slug: "pairing", Label: "Pairing", Karma: 50}}
+
+	if changes, kept := diff(specs, feats, true); len(changes) != 0 || len(kept) != 0 {
+		t.Errorf("diff() = %+v, %v, want no change", changes, kept)
+	}
+}
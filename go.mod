@@ -6,19 +6,19 @@ require (
 	github.com/AlecAivazis/survey/v2 v2.2.4
 	github.com/MakeNowJust/heredoc v1.0.0
 	github.com/briandowns/spinner v1.12.0
-	github.com/elazarl/goproxy v0.0.0-20201021153353-00ad82a08272 // indirect
 	github.com/fatih/color v1.7.0
 	github.com/gizak/termui/v3 v3.1.0
 	github.com/mattn/go-colorable v0.1.8 // indirect
+	github.com/mattn/go-isatty v0.0.12
 	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d // indirect
 	github.com/mitchellh/go-homedir v1.1.0
-	github.com/parnurzeal/gorequest v0.2.16
+	github.com/nsf/termbox-go v0.0.0-20190121233118-02980233997d
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.7.1
-	golang.org/x/crypto v0.0.0-20201124201722-c8d3bf9c5392 // indirect
-	golang.org/x/sys v0.0.0-20201201145000-ef89a241ccb3 // indirect
+	golang.org/x/crypto v0.0.0-20201124201722-c8d3bf9c5392
+	golang.org/x/sys v0.0.0-20201201145000-ef89a241ccb3
 	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1 // indirect
 	golang.org/x/text v0.3.4 // indirect
-	moul.io/http2curl v1.0.0 // indirect
+	gopkg.in/yaml.v2 v2.2.8
 )
@@ -0,0 +1,101 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// git runs a git command in the current repository and returns its trimmed output
+func git(args ...string) (string, error) {
+	return gitInput("", args...)
+}
+
+func gitInput(stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// gitPath returns the absolute path of a file in the git directory shared by all worktrees,
+// honoring core.hooksPath for hooks
+func gitPath(name string) (string, error) {
+	path, err := git("rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+
+	if name != "hooks" {
+		common, err := git("rev-parse", "--git-common-dir")
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(common, name)
+	}
+
+	return filepath.Abs(path)
+}
+
+// commit is what hooks need to know about a commit
+type commit struct {
+	sha      string
+	email    string
+	trailers []trailer
+}
+
+// readCommit returns the author and trailers of a commit
+func readCommit(sha string) (commit, error) {
+	out, err := git("show", "-s", "--format=%H%n%ae%n%(trailers:unfold)", sha)
+	if err != nil {
+		return commit{}, err
+	}
+
+	lines := strings.SplitN(out, "\n", 3)
+	if len(lines) < 2 {
+		return commit{}, fmt.Errorf("could not read commit %s", sha)
+	}
+
+	c := commit{sha: lines[0], email: lines[1]}
+	if len(lines) == 3 {
+		c.trailers = parseTrailers(lines[2])
+	}
+
+	return c, nil
+}
+
+// patchID identifies the changes of a commit, which survive rebases and amended messages.
+// Commits without changes, like merges, are identified by their hash.
+func patchID(sha string) (string, error) {
+	diff, err := git("show", "--format=", "--no-color", sha)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := gitInput(diff+"\n", "patch-id", "--stable")
+	if err != nil || out == "" {
+		return sha, err
+	}
+
+	return strings.Fields(out)[0], nil
+}
+
+// lastAction returns the reflog message of the last change to HEAD, like "commit (amend): ..."
+func lastAction() string {
+	out, _ := git("reflog", "-1", "--format=%gs", "HEAD")
+
+	return out
+}
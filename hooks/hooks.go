@@ -0,0 +1,221 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/spf13/cobra"
+)
+
+// hookNames are the git hooks cheers are sent from
+var hookNames = []string{"post-commit", "post-merge", "post-rewrite"}
+
+const (
+	blockStart = "# >>> karma >>>"
+	blockEnd   = "# <<< karma <<<"
+)
+
+// NewCmdHooks creates a hooks command
+func NewCmdHooks(client *api.Client, conf *config.Configuration) *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "hooks",
+		Short: "Send cheers from commit trailers",
+		Long: heredoc.Doc(`
+			Send cheers from commit trailers, with git hooks.
+
+			Once installed in a repository, your commits send cheers for their trailers:
+
+			  Cheers-to: alice, bob #pairing thanks for the help
+			  Co-authored-by: Carol Jones <carol@example.com>
+
+			Developers are matched to your team by name, GitHub login, email or alias.
+			Cheers-to takes an optional feat slug after a #, then an optional message.
+			Co-authors, and Cheers-to without a feat, are cheered for the feat set with
+			'git config karma.feat <slug>', pairing by default.
+
+			Only commits you authored send cheers. Each commit sends its cheers once,
+			even when amended, rebased or cherry-picked, as long as its changes stay
+			the same. Cheers that can't be sent right away are queued.
+		`),
+		Example: heredoc.Doc(`
+			$ karma hooks install
+			$ git commit -m "Fix flaky test" -m "Cheers-to: alice #review"
+		`),
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the hooks in the current repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+
+			return installRun(profile)
+		},
+	}
+	cmd.AddCommand(installCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the hooks from the current repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return uninstallRun()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:    "run <hook> [<args>...]",
+		Short:  "Send cheers for the commits a git hook was called for",
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			return hookRun(cmd.Context(), *client, *conf, args[0], args[1:])
+		},
+	})
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+// hookBlock is the snippet added to git hooks, pinned to a profile if one was given at install
+func hookBlock(hook string, profile string) string {
+	command := "karma hooks run " + hook
+	if profile != "" {
+		command += " --profile " + profile
+	}
+
+	return heredoc.Docf(`
+		%s
+		# Sends cheers from Cheers-to and Co-authored-by trailers, see 'karma hooks --help'
+		if command -v karma >/dev/null 2>&1; then
+		  %s "$@" || true
+		fi
+		%s
+	`, blockStart, command, blockEnd)
+}
+
+func installRun(profile string) error {
+	dir, err := gitPath("hooks")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, hook := range hookNames {
+		path := filepath.Join(dir, hook)
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		script := removeBlock(string(content))
+		if strings.TrimSpace(script) == "" {
+			script = "#!/bin/sh\n"
+		} else if !strings.HasPrefix(script, "#!/bin/sh") && !strings.HasPrefix(script, "#!/bin/bash") && !strings.HasPrefix(script, "#!/usr/bin/env bash") {
+			color.Yellow(fmt.Sprintf("Skipped %s, it isn't a shell script. Call 'karma hooks run %s \"$@\"' from it instead.", path, hook))
+			continue
+		}
+
+		if !strings.HasSuffix(script, "\n") {
+			script += "\n"
+		}
+		script += hookBlock(hook, profile)
+
+		if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+			return err
+		}
+		if err := os.Chmod(path, 0755); err != nil {
+			return err
+		}
+
+		fmt.Printf("Installed %s\n", path)
+	}
+
+	color.Green("✅ Your commits will now send cheers for their Cheers-to and Co-authored-by trailers.")
+
+	return nil
+}
+
+func uninstallRun() error {
+	dir, err := gitPath("hooks")
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hookNames {
+		path := filepath.Join(dir, hook)
+
+		content, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		script := removeBlock(string(content))
+		if script == string(content) {
+			continue
+		}
+
+		// drop hooks left with nothing but a shebang
+		if lines := strings.Split(strings.TrimSpace(script), "\n"); len(lines) == 1 && strings.HasPrefix(lines[0], "#!") {
+			err = os.Remove(path)
+		} else {
+			err = ioutil.WriteFile(path, []byte(script), 0755)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Uninstalled %s\n", path)
+	}
+
+	color.Green("✅ Commits won't send cheers anymore.")
+
+	return nil
+}
+
+// removeBlock removes the karma snippet from a hook script
+func removeBlock(script string) string {
+	start := strings.Index(script, blockStart)
+	end := strings.Index(script, blockEnd)
+	if start < 0 || end < start {
+		return script
+	}
+
+	return script[:start] + strings.TrimPrefix(script[end+len(blockEnd):], "\n")
+}
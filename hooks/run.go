@@ -0,0 +1,274 @@
+package hooks
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/krmdv/cli/api"
+	cheerCmd "github.com/krmdv/cli/cheer"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/queue"
+)
+
+// defaultFeat is cheered for co-authors and Cheers-to trailers without a feat, unless set with
+// 'git config karma.feat <slug>'
+const defaultFeat = "pairing"
+
+// hookTimeout bounds each API call of a hook, so a slow API doesn't hold git up. Cheers that time
+// out are queued and retried later.
+const hookTimeout = 5 * time.Second
+
+// hookRun sends the cheers requested by the trailers of the commits a hook was called for
+func hookRun(ctx context.Context, client api.Client, conf config.Configuration, hook string, args []string) error {
+	var shas []string
+	// rewritten maps commits to the ones they replace, whose cheers were already sent
+	rewritten := map[string]string{}
+	send := true
+
+	switch hook {
+	case "post-commit":
+		// amended and rebased commits are handled by post-rewrite
+		if action := lastAction(); strings.HasPrefix(action, "commit (amend)") || strings.HasPrefix(action, "rebase") {
+			return nil
+		}
+		shas = []string{"HEAD"}
+	case "post-merge":
+		out, err := git("rev-list", "--no-merges", "ORIG_HEAD..HEAD")
+		if err != nil {
+			return nil
+		}
+		shas = strings.Fields(out)
+	case "post-rewrite":
+		// rebased commits only carry their cheers over, amended ones may have new trailers
+		send = len(args) > 0 && args[0] == "amend"
+
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if fields := strings.Fields(scanner.Text()); len(fields) >= 2 {
+				shas = append(shas, fields[1])
+				rewritten[fields[1]] = fields[0]
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported hook %q, use one of %s", hook, strings.Join(hookNames, ", "))
+	}
+
+	me, _ := git("config", "user.email")
+
+	featSlug, _ := git("config", "karma.feat")
+	if featSlug == "" {
+		featSlug = defaultFeat
+	}
+
+	path, err := gitPath("karma-cheers")
+	if err != nil {
+		return err
+	}
+
+	ledger, err := loadLedger(path)
+	if err != nil {
+		return err
+	}
+
+	client = client.With(api.WithRetries(0), api.WithTimeout(hookTimeout))
+	h := hookSender{ctx: ctx, client: client, conf: conf, ledger: ledger}
+
+	for _, sha := range shas {
+		c, err := readCommit(sha)
+		if err != nil {
+			return err
+		}
+
+		if !strings.EqualFold(c.email, me) || len(c.trailers) == 0 {
+			continue
+		}
+
+		id, err := patchID(c.sha)
+		if err != nil {
+			return err
+		}
+
+		if old, ok := rewritten[c.sha]; ok {
+			if oldID, err := patchID(old); err == nil {
+				ledger.carry(oldID, id)
+			}
+		}
+
+		if !send {
+			continue
+		}
+
+		for _, t := range c.trailers {
+			if t.feat == "" {
+				t.feat = featSlug
+			}
+			h.cheer(c, id, t)
+		}
+	}
+
+	if err := ledger.save(); err != nil {
+		return err
+	}
+
+	if h.failed > 0 {
+		return fmt.Errorf("%d cheer(s) from commit trailers could not be sent", h.failed)
+	}
+
+	return nil
+}
+
+// hookSender sends cheers for trailers, once per commit changes, recipient and feat
+type hookSender struct {
+	ctx       context.Context
+	client    api.Client
+	conf      config.Configuration
+	ledger    *ledger
+	refreshed bool
+	failed    int
+}
+
+func (h *hookSender) cheer(c commit, id string, t trailer) {
+	user, err := h.matchUser(t)
+	if err != nil {
+		// co-authors outside the team are expected, only explicit cheers are worth a warning
+		if !t.coAuthor {
+			h.warn(c, fmt.Sprintf("%v in a trailer", err))
+		}
+		return
+	}
+
+	if user.ID == h.conf.User.ID {
+		return
+	}
+
+	feat, err := cheerCmd.MatchFeat(h.conf.Feats, t.feat)
+	if err != nil {
+		h.warn(c, fmt.Sprintf("%v, set the feat cheered by default with 'git config karma.feat <slug>'", err))
+		return
+	}
+
+	key := id + " " + user.ID + " " + feat.ID
+	if h.ledger.has(key) {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(h.conf.User.ID + " " + key))
+	cheer := api.Cheer{UserID: user.ID, FeatID: feat.ID, Msg: t.msg, IdempotencyKey: hex.EncodeToString(sum[:16])}
+
+	res, err := h.client.CreateCheer(h.ctx, cheer)
+	switch {
+	case api.IsTemporary(err):
+		if _, err := queue.Enqueue(cheer, user.Name, feat.Slug, err); err != nil {
+			h.warn(c, fmt.Sprintf("could not queue a cheer to %s: %v", user.Name, err))
+			return
+		}
+		fmt.Fprintf(os.Stderr, "karma: queued a cheer to %s for %s\n", user.Name, feat.Label)
+	case err != nil:
+		h.warn(c, fmt.Sprintf("could not cheer %s: %v", user.Name, err))
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "karma: cheered %s for %s, +%d pts\n", user.Name, feat.Label, res.Karma)
+	}
+
+	h.ledger.add(key)
+}
+
+// matchUser finds a trailer's developer by email first. The cached team is refreshed once if a
+// Cheers-to trailer names someone missing from it, not for co-authors who may not be in the team.
+func (h *hookSender) matchUser(t trailer) (api.User, error) {
+	for {
+		if t.email != "" {
+			if user, err := cheerCmd.MatchUser(h.conf.Users, t.email); err == nil {
+				return user, nil
+			}
+		}
+
+		user, err := cheerCmd.MatchUser(h.conf.Users, t.who)
+		if !cheerCmd.IsNoMatch(err) || h.refreshed || t.coAuthor {
+			return user, err
+		}
+
+		h.refreshed = true
+		cache, fetchErr := config.FetchTeamCache(h.ctx, h.client)
+		if fetchErr != nil {
+			return user, err
+		}
+		h.conf.Users, h.conf.Feats = cache.Team.Users, cache.Feats
+		config.SaveTeamCache(cache)
+	}
+}
+
+func (h *hookSender) warn(c commit, msg string) {
+	h.failed++
+	fmt.Fprintf(os.Stderr, "karma: %s (commit %.7s)\n", msg, c.sha)
+}
+
+// ledger records the cheers sent from commit trailers, by commit changes, recipient and feat
+type ledger struct {
+	path  string
+	keys  map[string]bool
+	dirty bool
+}
+
+func loadLedger(path string) (*ledger, error) {
+	l := &ledger{path: path, keys: map[string]bool{}}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			l.keys[line] = true
+		}
+	}
+
+	return l, nil
+}
+
+func (l *ledger) has(key string) bool {
+	return l.keys[key]
+}
+
+func (l *ledger) add(key string) {
+	if !l.keys[key] {
+		l.keys[key] = true
+		l.dirty = true
+	}
+}
+
+// carry marks the cheers sent for some changes as sent for their rewritten version too
+func (l *ledger) carry(from string, to string) {
+	if from == to {
+		return
+	}
+
+	for key := range l.keys {
+		if strings.HasPrefix(key, from+" ") {
+			l.add(to + strings.TrimPrefix(key, from))
+		}
+	}
+}
+
+func (l *ledger) save() error {
+	if !l.dirty {
+		return nil
+	}
+
+	var b strings.Builder
+	for key := range l.keys {
+		b.WriteString(key + "\n")
+	}
+
+	return ioutil.WriteFile(l.path, []byte(b.String()), 0644)
+}
@@ -0,0 +1,106 @@
+package hooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+)
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cheered")
+
+	l, err := loadLedger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	l.add("p1 u2 f1")
+	l.add("p1 u3 f1")
+	l.add("p2 u2 f1")
+	l.carry("p1", "p3")
+	l.carry("p2", "p2")
+
+	if err := l.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err = loadLedger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"p1 u2 f1", true},
+		{"p1 u3 f1", true},
+		{"p2 u2 f1", true},
+		{"p3 u2 f1", true},
+		{"p3 u3 f1", true},
+		{"p3 u2 f2", false},
+		{"p2 u3 f1", false},
+		{"p1", false},
+	}
+
+	for _, tt := range tests {
+		if got := l.has(tt.key); got != tt.want {
+			t.Errorf("has(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	if l.dirty {
+		t.Error("a loaded ledger should not need saving")
+	}
+}
+
+func TestLedgerCarryPrefix(t *testing.T) {
+	l := &ledger{keys: map[string]bool{"p1 u2 f1": true, "p10 u3 f1": true}}
+
+	l.carry("p1", "p4")
+
+	if !l.has("p4 u2 f1") {
+		t.Error("cheer for p1 was not carried")
+	}
+	if l.has("p40 u3 f1") || l.has("p4 u3 f1") {
+		t.Error("cheer for p10 was carried as if sent for p1")
+	}
+}
+
+func TestUnmatchedTrailers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected API call %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	var conf config.Configuration
+	conf.Users = []api.User{{ID: "u2", Name: "Alice Martin", Email: "alice@example.com"}}
+	conf.Feats = []api.Feat{{ID: "f1", Slug: "pairing", Label: "Pairing", Karma: 50}}
+
+	h := hookSender{
+		ctx:       context.Background(),
+		client:    api.NewClient(srv.URL, "token", "t1", "test"),
+		conf:      conf,
+		ledger:    &ledger{keys: map[string]bool{}},
+		refreshed: true,
+	}
+
+	c := commit{sha: "0123456789"}
+	h.cheer(c, "p1", trailer{who: "dependabot[bot]", email: "bot@example.com", feat: "pairing", coAuthor: true})
+	if h.failed != 0 {
+		t.Errorf("an unknown co-author counted as %d failure(s)", h.failed)
+	}
+
+	h.cheer(c, "p1", trailer{who: "zed", feat: "pairing"})
+	if h.failed != 1 {
+		t.Errorf("an unknown Cheers-to recipient counted as %d failure(s), want 1", h.failed)
+	}
+}
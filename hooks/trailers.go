@@ -0,0 +1,91 @@
+package hooks
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// trailer is a cheer requested by a commit trailer
+type trailer struct {
+	// who designates a developer: a name, login, email or alias
+	who string
+	// email is the address given with a name, if any
+	email string
+	// feat is the slug given after a #, if any
+	feat string
+	msg  string
+	// coAuthor tells the trailer is a Co-authored-by one, which often names bots or people
+	// outside the team
+	coAuthor bool
+}
+
+// parseTrailers extracts cheers from "Cheers-to: alice, bob #pairing thanks" and
+// "Co-authored-by: Name <email>" trailers
+func parseTrailers(raw string) []trailer {
+	var trailers []trailer
+
+	for _, line := range strings.Split(raw, "\n") {
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+
+		key, value := strings.ToLower(strings.TrimSpace(line[:i])), strings.TrimSpace(line[i+1:])
+
+		switch key {
+		case "cheers-to":
+			trailers = append(trailers, parseCheersTo(value)...)
+		case "co-authored-by":
+			if t, ok := parseAddress(value); ok {
+				t.coAuthor = true
+				trailers = append(trailers, t)
+			}
+		}
+	}
+
+	return trailers
+}
+
+func parseCheersTo(value string) []trailer {
+	var feat, msg string
+
+	if i := strings.Index(value, "#"); i >= 0 {
+		rest := strings.Fields(value[i+1:])
+		value = value[:i]
+
+		if len(rest) > 0 {
+			feat, msg = rest[0], strings.Join(rest[1:], " ")
+		}
+	}
+
+	var trailers []trailer
+	for _, who := range strings.Split(value, ",") {
+		t, ok := parseAddress(who)
+		if !ok {
+			continue
+		}
+
+		t.feat, t.msg = feat, msg
+		trailers = append(trailers, t)
+	}
+
+	return trailers
+}
+
+// parseAddress reads "Name <email>", "<email>", "email", "@login" or a plain name
+func parseAddress(value string) (trailer, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return trailer{}, false
+	}
+
+	if addr, err := mail.ParseAddress(value); err == nil {
+		who := addr.Name
+		if who == "" {
+			who = addr.Address
+		}
+		return trailer{who: who, email: addr.Address}, true
+	}
+
+	return trailer{who: strings.TrimPrefix(value, "@")}, true
+}
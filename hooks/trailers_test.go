@@ -0,0 +1,55 @@
+package hooks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []trailer
+	}{
+		{"none", "Signed-off-by: Alice <alice@example.com>\nnot a trailer", nil},
+		{
+			"cheers to several",
+			"Cheers-to: alice, @bobby #pairing thanks for the help",
+			[]trailer{
+				{who: "alice", feat: "pairing", msg: "thanks for the help"},
+				{who: "bobby", feat: "pairing", msg: "thanks for the help"},
+			},
+		},
+		{
+			"cheers to without feat",
+			"cheers-to:  Carol Jones <carol@example.com> ",
+			[]trailer{{who: "Carol Jones", email: "carol@example.com"}},
+		},
+		{
+			"cheers to with bare feat",
+			"Cheers-To: bob #review",
+			[]trailer{{who: "bob", feat: "review"}},
+		},
+		{
+			"empty recipients are skipped",
+			"Cheers-to: , alice,",
+			[]trailer{{who: "alice"}},
+		},
+		{
+			"co-authors",
+			"Co-authored-by: Alice Martin <alice@example.com>\nCo-Authored-By: bob@example.com\nCo-authored-by:",
+			[]trailer{
+				{who: "Alice Martin", email: "alice@example.com", coAuthor: true},
+				{who: "bob@example.com", email: "bob@example.com", coAuthor: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTrailers(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTrailers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderboard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/spf13/cobra"
+)
+
+// entryOutput is the structured standing of a team member
+type entryOutput struct {
+	api.RankingEntry
+	// Change is how many ranks the member went up since the previous period, nil if unranked then
+	Change *int `json:"change"`
+	Me     bool `json:"me"`
+}
+
+// leaderboardOutput is the structured leaderboard
+type leaderboardOutput struct {
+	api.Ranking
+	Entries []entryOutput `json:"entries"`
+}
+
+var (
+	periods  = []string{api.PeriodWeek, api.PeriodMonth, api.PeriodQuarter, api.PeriodAll}
+	rankings = []string{api.RankByKarma, api.RankByLevel, api.RankByCheersGiven}
+)
+
+// NewCmdLeaderboard creates a leaderboard command
+func NewCmdLeaderboard(client *api.Client, conf *config.Configuration) *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "leaderboard",
+		Short: "Rank your team by karma, level or cheers given",
+		Long: heredoc.Doc(`
+			Rank the members of your team over a period, by karma received, level reached
+			or cheers given, with how many ranks each went up or down since the previous
+			period. All time standings are compared with a week ago.
+
+			You are highlighted in the ranking.
+		`),
+		Example: heredoc.Doc(`
+			$ karma leaderboard
+			$ karma lb --period month --by cheers-given
+			$ karma lb --period all --by level --output json
+		`),
+		Aliases: []string{"lb"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			period, _ := cmd.Flags().GetString("period")
+			by, _ := cmd.Flags().GetString("by")
+
+			return leaderboardRun(cmd.Context(), *client, *conf, format, period, by)
+		},
+	}
+
+	cmd.SilenceUsage = true
+	cmd.Flags().StringP("period", "p", api.PeriodWeek, "period to rank over: "+strings.Join(periods, ", "))
+	cmd.Flags().StringP("by", "b", api.RankByKarma, "what to rank by: "+strings.Join(rankings, ", "))
+	cmd.RegisterFlagCompletionFunc("period", completeValues(periods))
+	cmd.RegisterFlagCompletionFunc("by", completeValues(rankings))
+
+	return cmd
+}
+
+func leaderboardRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format, period string, by string) error {
+	if !contains(periods, period) {
+		return fmt.Errorf("unknown period %q, use %s", period, strings.Join(periods, ", "))
+	}
+	if !contains(rankings, by) {
+		return fmt.Errorf("unknown ranking %q, use %s", by, strings.Join(rankings, ", "))
+	}
+
+	ranking, err := client.GetRanking(ctx, period, by)
+	if err != nil {
+		return err
+	}
+
+	out := leaderboardOutput{Ranking: ranking, Entries: []entryOutput{}}
+	for _, e := range ranking.Entries {
+		entry := entryOutput{RankingEntry: e, Me: e.UserID == conf.User.ID}
+		if e.PreviousRank > 0 {
+			change := e.PreviousRank - e.Rank
+			entry.Change = &change
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, out)
+	}
+
+	fmt.Println(color.New(color.Bold).Sprint(title(ranking)))
+
+	if len(out.Entries) == 0 {
+		fmt.Println("No one to rank yet.")
+		return nil
+	}
+
+	// lines are colored once aligned, as escape codes would throw tabwriter off
+	var table bytes.Buffer
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RANK\tCHANGE\tNAME\tKARMA\tLEVEL\tCHEERS GIVEN")
+	for _, e := range out.Entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d\n", e.Rank, change(e.Change), e.Name, e.Karma, e.Level, e.CheersGiven)
+	}
+	w.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	fmt.Println(lines[0])
+	for i, line := range lines[1:] {
+		switch e := out.Entries[i]; {
+		case e.Me:
+			color.New(color.FgCyan, color.Bold).Println(line)
+		case e.Change != nil && *e.Change > 0:
+			fmt.Println(strings.Replace(line, change(e.Change), color.GreenString(change(e.Change)), 1))
+		case e.Change != nil && *e.Change < 0:
+			fmt.Println(strings.Replace(line, change(e.Change), color.RedString(change(e.Change)), 1))
+		default:
+			fmt.Println(line)
+		}
+	}
+
+	return nil
+}
+
+// title describes the period and ranking of a leaderboard
+func title(ranking api.Ranking) string {
+	what := map[string]string{
+		api.RankByKarma:       "karma received",
+		api.RankByLevel:       "level",
+		api.RankByCheersGiven: "cheers given",
+	}[ranking.By]
+
+	if ranking.Period == api.PeriodAll {
+		return fmt.Sprintf("All time leaderboard by %s", what)
+	}
+
+	return fmt.Sprintf("Leaderboard by %s, %s to %s", what, ranking.From.Local().Format("Jan 2"), ranking.To.Local().Format("Jan 2"))
+}
+
+// change shows a rank change, e.g. "▲ 2", "▼ 1", "=" or "new"
+func change(c *int) string {
+	switch {
+	case c == nil:
+		return "new"
+	case *c > 0:
+		return fmt.Sprintf("▲ %d", *c)
+	case *c < 0:
+		return fmt.Sprintf("▼ %d", -*c)
+	default:
+		return "="
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func completeValues(values []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,114 @@
+package login
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	"github.com/mattn/go-isatty"
+)
+
+// slowDownStep is added to the polling interval whenever the API asks to slow down
+var slowDownStep = 5 * time.Second
+
+// readToken reads a token piped on standard input
+func readToken(r io.Reader) (string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("could not read the token: %w", err)
+	}
+
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", errors.New("no token found on standard input")
+	}
+
+	return token, nil
+}
+
+// deviceLogin shows a one-time code for the user to approve in a browser, then waits for the
+// API to issue a token
+func deviceLogin(ctx context.Context, client api.Client) (string, error) {
+	code, err := client.StartDeviceLogin(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprint(os.Stderr, "! First copy your one-time code: ")
+	color.New(color.Bold).Fprintln(os.Stderr, code.UserCode)
+
+	interactive := isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stderr.Fd())
+
+	if interactive {
+		fmt.Fprintf(os.Stderr, "👉 Press Enter to open %s in your browser...", code.VerificationURL)
+		bufio.NewReader(os.Stdin).ReadString('\n')
+
+		if err := openBrowser(code.VerificationURL); err != nil {
+			color.New(color.FgYellow).Fprintf(os.Stderr, "Couldn't open a browser, please navigate to %s\n", code.VerificationURL)
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "👉 Then open %s in a browser to approve this device.\n", code.VerificationURL)
+	}
+
+	if interactive {
+		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
+		s.Suffix = " Waiting for approval..."
+		s.Start()
+		defer s.Stop()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, code.Expiry())
+	defer cancel()
+
+	interval := code.PollInterval()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", errors.New("the one-time code expired, please run 'karma login' again")
+			}
+			return "", api.ErrCancelled
+		case <-time.After(interval):
+		}
+
+		token, err := client.PollDeviceLogin(ctx, code.DeviceCode)
+		if err != nil {
+			return "", err
+		}
+
+		switch token.Status {
+		case api.DeviceApproved:
+			return token.Token, nil
+		case api.DeviceSlowDown:
+			interval += slowDownStep
+		}
+	}
+}
+
+// openBrowser opens a URL with the default browser of the platform
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	return cmd.Start()
+}
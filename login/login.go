@@ -17,72 +17,105 @@ limitations under the License.
 package login
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 
+	"github.com/MakeNowJust/heredoc"
 	"github.com/fatih/color"
 	"github.com/krmdv/cli/api"
 	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
 
-	homedir "github.com/mitchellh/go-homedir"
-	"github.com/parnurzeal/gorequest"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // NewCmdLogin creates a login command
-func NewCmdLogin() *cobra.Command {
+func NewCmdLogin(client *api.Client) *cobra.Command {
 
 	var cmd = &cobra.Command{
-		Use:   "login <token>",
+		Use:   "login [<token>]",
 		Short: "Login to Karma",
-		Long:  `Login to Karma`,
-		Args:  cobra.MinimumNArgs(1),
+		Long: heredoc.Doc(`
+			Login to Karma.
+
+			Without arguments, a one-time code is shown to approve this device in your
+			browser. Tokens created elsewhere can be piped with --with-token, which keeps
+			them out of your shell history, or passed as an argument.
+		`),
+		Example: heredoc.Doc(`
+			# approve this device in your browser
+			$ karma login
+
+			# read the token from a file or a secret manager
+			$ karma login --with-token < karma-token.txt
+		`),
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return loginRun(args[0])
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("token") {
+				return errors.New("--token would leave your token in your shell history, pipe it with --with-token instead")
+			}
+
+			withToken, _ := cmd.Flags().GetBool("with-token")
+
+			var token string
+			switch {
+			case withToken && len(args) > 0:
+				return errors.New("pass the token either as an argument or with --with-token, not both")
+			case withToken:
+				token, err = readToken(os.Stdin)
+			case len(args) > 0:
+				token = args[0]
+			default:
+				token, err = deviceLogin(cmd.Context(), *client)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			return loginRun(cmd.Context(), *client, token, format)
 		},
 	}
 
 	cmd.SilenceUsage = true
+	cmd.Flags().Bool("with-token", false, "read the token from standard input")
+	// tokens passed as flags end up in the shell history, --token is only kept to say so
 	cmd.Flags().StringP("token", "t", "", "your karma api token")
-	cmd.Flags().StringP("org", "o", "", "active github organization")
-	cmd.Flags().StringP("slack", "s", "", "your slack notifications webhook URL")
+	_ = cmd.Flags().MarkHidden("token")
 
 	return cmd
 }
 
-func loginRun(token string) error {
-	type userRes struct {
-		Name string `json:"name"`
-		ID   string `json:"id"`
+func loginRun(ctx context.Context, client api.Client, token string, format output.Format) error {
+	user, err := client.WithToken(token).GetMe(ctx)
+	if err != nil {
+		return err
 	}
 
-	var user userRes
+	config.Set("user.id", user.ID)
+	config.Set("user.name", user.Name)
+	config.Set("users", "")
+	config.Set("team", "")
+	config.Set("feats", "")
 
-	resp, _, errs := gorequest.New().
-		Get(config.Host()+"/users/me").
-		Set("Authorization", fmt.Sprintf("token %s", token)).
-		EndStruct(&user)
-
-	if len(errs) != 0 {
-		return errs[0]
+	if err := config.SetSecret("token", token); err != nil {
+		return fmt.Errorf("could not save your token: %w", err)
 	}
 
-	success := resp.StatusCode >= 200 && resp.StatusCode < 300
-
-	if !success {
-		return api.HandleHTTPError(resp)
+	if err := config.Write(); err != nil {
+		return err
 	}
 
-	viper.Set("user.id", user.ID)
-	viper.Set("user.name", user.Name)
-	viper.Set("token", token)
-	viper.Set("users", "")
-	viper.Set("team", "")
-	viper.Set("feats", "")
-
-	home, _ := homedir.Dir()
-
-	viper.WriteConfigAs(home + "/.karma.yaml")
+	if format.Structured() {
+		return output.Print(os.Stdout, format, user)
+	}
 
 	fmt.Println(`
 		                                                                                
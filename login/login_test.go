@@ -0,0 +1,305 @@
+package login
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/devserver"
+	"github.com/mitchellh/go-homedir"
+)
+
+// setup points the configuration to a temporary home and the file credential store
+func setup(t *testing.T) string {
+	home := t.TempDir()
+	homedir.DisableCache = true
+
+	vars := map[string]string{
+		"HOME":                        home,
+		"KARMA_CREDENTIAL_STORE":      "file",
+		"KARMA_CREDENTIAL_PASSPHRASE": "test",
+		"KARMA_TOKEN":                 "",
+		"KARMA_PROFILE":               "",
+	}
+
+	for name, value := range vars {
+		previous, set := os.LookupEnv(name)
+		if value == "" {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, value)
+		}
+
+		name := name
+		t.Cleanup(func() {
+			if set {
+				os.Setenv(name, previous)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+
+	return home
+}
+
+// devServer serves the dev server fixtures, approving every device login as soon as it starts
+// the way a user would on its /device page
+func devServer(t *testing.T, home string) *httptest.Server {
+	store, err := devserver.OpenStore(filepath.Join(home, "fixtures.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var srv *httptest.Server
+	handler := devserver.NewServer(store)
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth/device" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+
+		var code api.DeviceCode
+		if err := json.Unmarshal(rec.Body.Bytes(), &code); err != nil {
+			t.Error(err)
+		}
+
+		form := url.Values{"user_code": {code.UserCode}, "action": {"approve"}}
+		if _, err := http.PostForm(srv.URL+"/device", form); err != nil {
+			t.Error(err)
+		}
+
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func login(t *testing.T, srv *httptest.Server, args ...string) error {
+	client := api.NewClient(srv.URL, "", "", "test")
+
+	cmd := NewCmdLogin(&client)
+	cmd.SetArgs(args)
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+
+	return cmd.ExecuteContext(context.Background())
+}
+
+func checkSaved(t *testing.T, token string) {
+	t.Helper()
+
+	saved, err := config.Secret("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved != token {
+		t.Errorf("saved token %q, want %q", saved, token)
+	}
+
+	content, err := ioutil.ReadFile(config.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(content, []byte(token)) {
+		t.Errorf("the token was written to the configuration file:\n%s", content)
+	}
+}
+
+func TestDeviceLogin(t *testing.T) {
+	srv := devServer(t, setup(t))
+
+	if err := login(t, srv); err != nil {
+		t.Fatal(err)
+	}
+
+	checkSaved(t, "dev-token")
+}
+
+func TestWithToken(t *testing.T) {
+	home := setup(t)
+	srv := devServer(t, home)
+
+	piped := filepath.Join(home, "token.txt")
+	if err := ioutil.WriteFile(piped, []byte("  dev-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(piped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	if err := login(t, srv, "--with-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	checkSaved(t, "dev-token")
+
+	for _, args := range [][]string{{"--with-token", "dev-token"}, {"--token", "dev-token"}} {
+		if err := login(t, srv, args...); err == nil {
+			t.Errorf("karma login %v succeeded, want an error", args)
+		}
+	}
+}
+
+func TestReadToken(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   bool
+	}{
+		{input: "dev-token", want: "dev-token"},
+		{input: "\n  dev-token \r\n", want: "dev-token"},
+		{input: "", err: true},
+		{input: " \n", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := readToken(strings.NewReader(tt.input))
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("readToken(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+// stubDevice answers device logins with a code and then the given poll responses, the last one
+// being repeated, recording when polls happen
+type stubDevice struct {
+	code  api.DeviceCode
+	polls []func(w http.ResponseWriter)
+
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (s *stubDevice) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/auth/device":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.code)
+	case "/auth/device/token":
+		s.mu.Lock()
+		s.times = append(s.times, time.Now())
+		i := len(s.times) - 1
+		s.mu.Unlock()
+
+		if i >= len(s.polls) {
+			i = len(s.polls) - 1
+		}
+		s.polls[i](w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func status(status string, token string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.DeviceToken{Status: status, Token: token})
+	}
+}
+
+func TestDeviceLoginOutcomes(t *testing.T) {
+	step := slowDownStep
+	slowDownStep = 200 * time.Millisecond
+	t.Cleanup(func() { slowDownStep = step })
+
+	denied := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "the login was denied"})
+	}
+
+	tests := []struct {
+		name  string
+		code  api.DeviceCode
+		polls []func(w http.ResponseWriter)
+		token string
+		err   string
+		// gaps are the minimum delays between successive polls
+		gaps []time.Duration
+	}{
+		{
+			name:  "approved",
+			code:  api.DeviceCode{DeviceCode: "d1", UserCode: "AB-CD", Interval: 1},
+			polls: []func(w http.ResponseWriter){status(api.DevicePending, ""), status(api.DeviceApproved, "t1")},
+			token: "t1",
+			gaps:  []time.Duration{time.Second},
+		},
+		{
+			name:  "slow down",
+			code:  api.DeviceCode{DeviceCode: "d2", UserCode: "AB-CD", Interval: 1},
+			polls: []func(w http.ResponseWriter){status(api.DeviceSlowDown, ""), status(api.DeviceApproved, "t2")},
+			token: "t2",
+			gaps:  []time.Duration{time.Second + 200*time.Millisecond},
+		},
+		{
+			name:  "denied",
+			code:  api.DeviceCode{DeviceCode: "d3", UserCode: "AB-CD", Interval: 1},
+			polls: []func(w http.ResponseWriter){status(api.DevicePending, ""), denied},
+			err:   "denied",
+		},
+		{
+			name:  "expired",
+			code:  api.DeviceCode{DeviceCode: "d4", UserCode: "AB-CD", Interval: 2, ExpiresIn: 1},
+			polls: []func(w http.ResponseWriter){status(api.DevicePending, "")},
+			err:   "expired",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stub := &stubDevice{code: tt.code, polls: tt.polls}
+			srv := httptest.NewServer(stub)
+			defer srv.Close()
+
+			token, err := deviceLogin(context.Background(), api.NewClient(srv.URL, "", "", "test", api.WithRetries(0)))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("deviceLogin() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != tt.token {
+				t.Errorf("deviceLogin() = %q, want %q", token, tt.token)
+			}
+
+			for i, gap := range tt.gaps {
+				if got := stub.times[i+1].Sub(stub.times[i]); got < gap {
+					t.Errorf("poll %d came %v after the previous one, want at least %v", i+2, got, gap)
+				}
+			}
+		})
+	}
+}
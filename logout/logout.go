@@ -0,0 +1,177 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logout
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/krmdv/cli/queue"
+	"github.com/spf13/cobra"
+)
+
+// logoutOutput is the structured report of what was removed
+type logoutOutput struct {
+	Profile string   `json:"profile"`
+	Revoked bool     `json:"revoked"`
+	Removed []string `json:"removed"`
+	// RevokeError explains why the token could not be revoked server-side, if it could not
+	RevokeError string `json:"revokeError,omitempty"`
+}
+
+// NewCmdLogout creates a logout command
+func NewCmdLogout(client *api.Client, conf *config.Configuration) *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "logout",
+		Short: "Logout from Karma",
+		Long: heredoc.Doc(`
+			Logout from Karma.
+
+			The API token is revoked server-side when possible, then removed from the
+			credential store along with the user, team, users and feats saved in the
+			active profile. Cheers still queued are sent first, and dropped if they
+			cannot be.
+		`),
+		Example: heredoc.Doc(`
+			# sign out of every profile before handing over a laptop
+			$ for p in $(karma profile list --output json | jq -r '.[].name'); do karma logout --profile $p; done
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return logoutRun(cmd.Context(), *client, *conf, format)
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func logoutRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format) error {
+	out := logoutOutput{Profile: config.Profile(), Removed: []string{}}
+
+	if conf.Token != "" && os.Getenv("KARMA_TOKEN") == "" {
+		if conf.Team.ID != "" {
+			queue.AutoFlush(ctx, client)
+		}
+
+		if err := client.RevokeToken(ctx); err != nil && api.Classify(err) != api.KindAuth {
+			out.RevokeError = err.Error()
+		} else {
+			out.Revoked = err == nil
+		}
+	}
+
+	store, err := config.DeleteSecrets()
+	if err != nil {
+		return fmt.Errorf("could not remove your token from the %s credential store: %w", store, err)
+	}
+	if store != "" && conf.Token != "" && os.Getenv("KARMA_TOKEN") == "" {
+		out.Removed = append(out.Removed, fmt.Sprintf("API token, from the %s credential store", store))
+	}
+
+	if conf.User.Name != "" {
+		out.Removed = append(out.Removed, fmt.Sprintf("user %s", conf.User.Name))
+	}
+	if conf.Team.Name != "" {
+		out.Removed = append(out.Removed, fmt.Sprintf("team %s", conf.Team.Name))
+	}
+	if len(conf.Users) > 0 {
+		out.Removed = append(out.Removed, plural(len(conf.Users), "cached user"))
+	}
+	if len(conf.Feats) > 0 {
+		out.Removed = append(out.Removed, plural(len(conf.Feats), "cached feat"))
+	}
+
+	if config.ProfileExists(config.Profile()) {
+		for _, key := range []string{"token", "user", "team", "users", "feats", "synced_at"} {
+			config.Set(key, "")
+		}
+
+		if err := config.Write(); err != nil {
+			return err
+		}
+	}
+
+	q, err := queue.Open(config.QueuePath(), true)
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	if n := len(q.Entries); n > 0 {
+		q.Entries = nil
+		if err := q.Save(); err != nil {
+			return err
+		}
+		out.Removed = append(out.Removed, plural(n, "unsent cheer"))
+	}
+
+	if err := os.Remove(config.DashboardCachePath()); err == nil {
+		out.Removed = append(out.Removed, "cached dashboard")
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, out)
+	}
+
+	if len(out.Removed) == 0 && !out.Revoked {
+		fmt.Println("Nothing to remove, you're not logged in.")
+	}
+
+	if out.Revoked {
+		color.Green("✅ Revoked your API token.")
+	}
+	if out.RevokeError != "" {
+		color.Yellow(fmt.Sprintf("Heads up! Couldn't revoke your API token, it stays valid until it expires: %s", out.RevokeError))
+	}
+
+	if len(out.Removed) > 0 {
+		fmt.Printf("Removed from profile %s:\n", out.Profile)
+		for _, item := range out.Removed {
+			fmt.Printf("  - %s\n", item)
+		}
+	}
+
+	if os.Getenv("KARMA_TOKEN") != "" {
+		color.Yellow("👉 KARMA_TOKEN is set and still used by later commands, unset it to sign out completely.")
+	}
+
+	return nil
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,38 @@
+package me
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+)
+
+// cachedDashboard is the last dashboard fetched, shown when the API is unreachable
+type cachedDashboard struct {
+	SavedAt   time.Time     `json:"savedAt"`
+	Dashboard api.Dashboard `json:"dashboard"`
+}
+
+func saveCache(data api.Dashboard) error {
+	content, err := json.Marshal(cachedDashboard{SavedAt: time.Now(), Dashboard: data})
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(config.DashboardCachePath(), content, 0600)
+}
+
+func loadCache() (cachedDashboard, error) {
+	var cached cachedDashboard
+
+	content, err := ioutil.ReadFile(config.DashboardCachePath())
+	if err != nil {
+		return cached, err
+	}
+
+	err = json.Unmarshal(content, &cached)
+
+	return cached, err
+}
@@ -0,0 +1,90 @@
+package me
+
+import (
+	ui "github.com/gizak/termui/v3"
+	tb "github.com/nsf/termbox-go"
+)
+
+// poller forwards the terminal events the dashboard handles. Unlike termui's PollEvents, which
+// reads the terminal for good, it can be paused so that prompts shown over the dashboard get
+// every keystroke.
+type poller struct {
+	events chan ui.Event
+	done   chan struct{}
+}
+
+func newPoller() *poller {
+	p := &poller{events: make(chan ui.Event)}
+	p.resume()
+
+	return p
+}
+
+// resume starts forwarding events again, once the terminal is initialized
+func (p *poller) resume() {
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+
+		for {
+			e := tb.PollEvent()
+			if e.Type == tb.EventInterrupt {
+				return
+			}
+
+			if event, ok := convertEvent(e); ok {
+				p.events <- event
+			}
+		}
+	}()
+}
+
+// pause stops reading the terminal, dropping events not handled yet
+func (p *poller) pause() {
+	interrupted := make(chan struct{})
+	go func() {
+		tb.Interrupt()
+		close(interrupted)
+	}()
+
+	for {
+		select {
+		case <-p.events:
+		case <-p.done:
+			<-interrupted
+			return
+		}
+	}
+}
+
+var keyIDs = map[tb.Key]string{
+	tb.KeyEnter:      "<Enter>",
+	tb.KeyEsc:        "<Escape>",
+	tb.KeyBackspace:  "<Backspace>",
+	tb.KeyBackspace2: "<Backspace>",
+	tb.KeyTab:        "<Tab>",
+	tb.KeySpace:      "<Space>",
+	tb.KeyArrowUp:    "<Up>",
+	tb.KeyArrowDown:  "<Down>",
+	tb.KeyArrowLeft:  "<Left>",
+	tb.KeyArrowRight: "<Right>",
+	tb.KeyCtrlC:      "<C-c>",
+}
+
+// convertEvent names events the way termui does, for the keys the dashboard handles
+func convertEvent(e tb.Event) (ui.Event, bool) {
+	switch e.Type {
+	case tb.EventResize:
+		return ui.Event{Type: ui.ResizeEvent, ID: "<Resize>", Payload: ui.Resize{Width: e.Width, Height: e.Height}}, true
+	case tb.EventKey:
+		if e.Ch != 0 && e.Mod == 0 {
+			return ui.Event{Type: ui.KeyboardEvent, ID: string(e.Ch)}, true
+		}
+		if id, ok := keyIDs[e.Key]; ok && e.Ch == 0 {
+			return ui.Event{Type: ui.KeyboardEvent, ID: id}, true
+		}
+	}
+
+	return ui.Event{}, false
+}
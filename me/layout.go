@@ -0,0 +1,127 @@
+package me
+
+import (
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+)
+
+// compactWidth is the terminal width below which the dashboard stacks its widgets
+// and hides the feats list, so the leaderboard and events log keep a usable width
+const compactWidth = 100
+
+// screen holds the dashboard widgets and lays them out for the terminal size
+type screen struct {
+	welcome     *widgets.Paragraph
+	multiplier  *widgets.Paragraph
+	gauge       *widgets.Gauge
+	feats       *widgets.List
+	tip         *widgets.Paragraph
+	footer      *widgets.Paragraph
+	leaderboard *widgets.BarChart
+	logs        *widgets.Table
+
+	// details pops over the dashboard when set
+	details *widgets.Paragraph
+
+	grid    *ui.Grid
+	compact bool
+
+	conf         config.Configuration
+	stats        api.Stats
+	focus        int
+	featsData    []string
+	featsTouched bool
+	logEntries   []api.LogEntry
+	logRows      [][]string
+	logStyles    []ui.Style
+	logOffset    int
+	logSelected  int
+	teammate     int
+}
+
+// resize lays out the widgets on a grid filling the given terminal size
+func (s *screen) resize(width int, height int) {
+	grid := ui.NewGrid()
+	grid.SetRect(0, 0, width, height)
+
+	// widgets only learn their size when drawn, so size the log columns from the layout ratios
+	logsWidth := width
+
+	if width < compactWidth {
+		grid.Set(
+			ui.NewRow(5.0/30,
+				ui.NewCol(0.7, s.welcome),
+				ui.NewCol(0.3, s.multiplier),
+			),
+			ui.NewRow(3.0/30, ui.NewCol(1, s.gauge)),
+			ui.NewRow(8.0/30, ui.NewCol(1, s.leaderboard)),
+			ui.NewRow(14.0/30, ui.NewCol(1, s.logs)),
+		)
+	} else {
+		grid.Set(
+			ui.NewRow(27.0/30,
+				ui.NewCol(0.42,
+					ui.NewRow(5.0/27, s.welcome),
+					ui.NewRow(3.0/27,
+						ui.NewCol(0.8, s.gauge),
+						ui.NewCol(0.2, s.multiplier),
+					),
+					ui.NewRow(16.0/27, s.feats),
+					ui.NewRow(3.0/27, s.tip),
+				),
+				ui.NewCol(0.58,
+					ui.NewRow(8.0/27, s.leaderboard),
+					ui.NewRow(19.0/27, s.logs),
+				),
+			),
+			ui.NewRow(3.0/30, ui.NewCol(1, s.footer)),
+		)
+		logsWidth = int(float64(width) * 0.58)
+	}
+
+	s.grid = grid
+	s.compact = width < compactWidth
+	if s.compact && s.focus == focusFeats {
+		s.focus = focusLogs
+	}
+	s.logs.ColumnWidths = logsColumnWidths(logsWidth - 2)
+}
+
+// logsColumnWidths shares the width of the events log between its columns,
+// giving the message column whatever is left
+func logsColumnWidths(width int) []int {
+	widths := []int{6, 0, 0, 0, 6, 0}
+
+	rest := width - widths[0] - widths[4]
+	if rest < 4 {
+		rest = 4
+	}
+
+	widths[1] = rest / 5
+	widths[2] = rest / 5
+	widths[3] = rest / 5
+	widths[5] = rest - widths[1] - widths[2] - widths[3]
+
+	return widths
+}
+
+func (s *screen) render() {
+	s.applyFocus()
+	s.showLogs()
+
+	if s.details == nil {
+		ui.Render(s.grid)
+	} else {
+		width, height := ui.TerminalDimensions()
+		w, h := 60, 10
+		if w > width-4 {
+			w = width - 4
+		}
+		s.details.SetRect((width-w)/2, (height-h)/2, (width+w)/2, (height+h)/2)
+
+		// a single render, so the pop-over does not flicker
+		ui.Render(s.grid, s.details)
+	}
+}
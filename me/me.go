@@ -1,44 +1,27 @@
 package me
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/fatih/color"
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
 	"github.com/krmdv/cli/api"
+	cheerCmd "github.com/krmdv/cli/cheer"
 	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
-type dashboard struct {
-	User struct {
-		ID    string `json:"id"`
-		Stats struct {
-			Level            int   `json:"level"`
-			Multiplier       int   `json:"multiplier"`
-			Progress         int   `json:"progress"`
-			KarmaToNextLevel int64 `json:"karmaToNextLevel"`
-		} `json:"stats"`
-		TotalAccruedKarma int64 `json:"totalAccruedKarma"`
-	} `json:"user"`
-	Leaderboard struct {
-		Names  []string  `json:"names"`
-		Levels []float64 `json:"levels"`
-	} `json:"leaderboard"`
-	Logs []struct {
-		Ago         string `json:"ago"`
-		From        string `json:"from"`
-		ToUser      string `json:"toUser"`
-		Karma       int    `json:"karma"`
-		FeatID      string `json:"featId"`
-		CurrentUser bool   `json:"currentUser"`
-	} `json:"logs"`
-}
-
 // NewCmdMe displays dashboard
-func NewCmdMe(client api.Client, conf config.Configuration) *cobra.Command {
+func NewCmdMe(client *api.Client, conf *config.Configuration) *cobra.Command {
 
 	var cmd = &cobra.Command{
 		Use:   "me",
@@ -48,83 +31,143 @@ func NewCmdMe(client api.Client, conf config.Configuration) *cobra.Command {
 				return err
 			}
 
-			return meRun(client, conf)
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			// Full-screen dashboard only makes sense in an interactive terminal
+			plain, _ := cmd.Flags().GetBool("plain")
+			if !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()) {
+				plain = true
+			}
+
+			refresh, _ := cmd.Flags().GetDuration("refresh")
+
+			return meRun(cmd.Context(), *client, *conf, format, plain, refresh)
 		},
 	}
 
+	cmd.SilenceUsage = true
+	cmd.Flags().Bool("plain", false, "print a static text report instead of the dashboard")
+	cmd.Flags().Duration("refresh", 30*time.Second, "how often the dashboard is refreshed, 0 to disable")
+
 	return cmd
 }
 
-func meRun(client api.Client, conf config.Configuration) error {
-	var data dashboard
+func meRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format, plain bool, refresh time.Duration) error {
+	data, err := client.GetDashboard(ctx)
 
-	client.Get("/dashboard", &data)
+	// fall back to the last dashboard fetched when the API is unreachable
+	var staleSince time.Time
+	if kind := api.Classify(err); err != nil {
+		cached, cacheErr := loadCache()
+		if (kind != api.KindNetwork && kind != api.KindServer) || cacheErr != nil {
+			if hint := kind.Hint(); hint != "" {
+				return fmt.Errorf("%w\n👉 %s", err, hint)
+			}
+			return err
+		}
 
-	if err := ui.Init(); err != nil {
-		return err
+		data, staleSince = cached.Dashboard, cached.SavedAt
+		color.New(color.FgYellow).Fprintf(os.Stderr, "%v\n👉 %s\nShowing your dashboard as of %s.\n", err, kind.Hint(), staleSince.Format("Jan 2 15:04"))
+	} else {
+		saveCache(data)
 	}
-	defer ui.Close()
 
-	welcomeBox := textBox("👋 Welcome", fmt.Sprintf("You are a level %v karma developer.\nYou earned %s karma pts in total.\nHit Q to quit.", data.User.Stats.Level, formatNumber(data.User.TotalAccruedKarma)), true)
-	gauge := gauge()
-	multiplierBox := textBox("Mul.", fmt.Sprintf(" %vx", data.User.Stats.Multiplier), false)
-	featsData := make([]string, 0, len(conf.Feats))
-	featsList := list("Feats (SLUG)", featsData)
-	tip := textBox("Tip", "Cheer with 'karma c DEV_NAME -f SLUG'", true)
-	footer := textBox("Info", "Running karma CLI v1.0.0. Check docs at https://docs.getkarma.dev. Thanks for being awesome 😍.", true)
-	leaderBoard := leaderboard(data.Leaderboard.Names, data.Leaderboard.Levels)
-	logs := logs(data, conf)
-
-	welcomeBox.SetRect(0, 0, 42, 5)
-	gauge.SetRect(0, 5, 34, 8)
-	multiplierBox.SetRect(35, 5, 42, 8)
-	featsList.SetRect(0, 8, 42, 24)
-	tip.SetRect(0, 24, 42, 27)
-	footer.SetRect(0, 27, 100, 30)
-	leaderBoard.SetRect(43, 0, 100, 8)
-	logs.SetRect(43, 8, 100, 27)
+	if format.Structured() {
+		return output.Print(os.Stdout, format, data)
+	}
 
-	for _, f := range conf.Feats {
-		featsData = append(featsData, fmt.Sprintf("%s (%s)", f.Label, f.Slug))
+	if plain {
+		return printReport(os.Stdout, data, conf)
 	}
 
-	gaugePercent := data.User.Stats.Progress
+	if err := ui.Init(); err != nil {
+		return err
+	}
+	defer ui.Close()
 
-	draw := func(count int) {
-		if gauge.Percent < gaugePercent {
-			gauge.Percent++
-			gauge.Label = fmt.Sprintf("%v%%", gauge.Percent)
-		} else {
-			gauge.Label = fmt.Sprintf("%v%% - %s pts to lvl %v", gaugePercent, formatNumber(data.User.Stats.KarmaToNextLevel), data.User.Stats.Level+1)
-		}
+	s := newScreen(conf)
+	s.update(data, time.Now())
+	s.setStale(staleSince)
+	s.resize(ui.TerminalDimensions())
 
-		if count%20 == 0 {
-			featsList.Rows = featsData[(count/20)%(len(featsData)):]
-		}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		ui.Render(welcomeBox, multiplierBox, gauge, featsList, tip, footer, leaderBoard, logs)
-	}
+	// refreshes must not print retry notices over the dashboard
+	updates, refreshNow := poll(ctx, client.With(api.WithNotifier(func(string) {}), api.WithRetries(0)), refresh)
 
 	tickerCount := 1
-	draw(tickerCount)
+	s.tick(tickerCount)
 	tickerCount++
-	uiEvents := ui.PollEvents()
+	events := newPoller()
 	ticker := time.NewTicker(time.Second / 20).C
 	for {
 		select {
-		case e := <-uiEvents:
+		case e := <-events.events:
 			switch e.ID {
 			case "q", "<C-c>":
 				return nil
+			case "<Resize>":
+				payload := e.Payload.(ui.Resize)
+				s.resize(payload.Width, payload.Height)
+				ui.Clear()
+				s.render()
+			default:
+				if teammate := s.handle(e); teammate != "" {
+					if err := cheerTeammate(ctx, client, conf, events, teammate); err != nil {
+						return err
+					}
+					s.resize(ui.TerminalDimensions())
+					refreshNow()
+				}
+				s.render()
 			}
+		case u := <-updates:
+			if u.err != nil {
+				s.logs.Title = fmt.Sprintf("Karma events log · refresh failed at %s", u.at.Format("15:04"))
+			} else {
+				s.update(u.data, u.at)
+				s.setStale(time.Time{})
+				saveCache(u.data)
+			}
+			s.render()
 		case <-ticker:
-			draw(tickerCount)
+			s.tick(tickerCount)
 			tickerCount++
 		}
 	}
 
 }
 
+// stdin reads the confirmation to go back to the dashboard, keeping what was typed ahead
+var stdin = bufio.NewReader(os.Stdin)
+
+// cheerTeammate leaves the dashboard for the cheer prompts, then comes back to it
+func cheerTeammate(ctx context.Context, client api.Client, conf config.Configuration, events *poller, teammate string) error {
+	events.pause()
+	ui.Close()
+
+	err := cheerCmd.Interactive(ctx, client, conf, teammate)
+	if err == terminal.InterruptErr {
+		fmt.Println("interrupted")
+	} else if err != nil {
+		color.Red(err.Error())
+	}
+
+	fmt.Print("Press Enter to go back to your dashboard...")
+	stdin.ReadString('\n')
+
+	if err := ui.Init(); err != nil {
+		return err
+	}
+	events.resume()
+
+	return nil
+}
+
 func textBox(title string, text string, grey bool) *widgets.Paragraph {
 	w := widgets.NewParagraph()
 	w.Title = title
@@ -179,49 +222,57 @@ func list(title string, data []string) *widgets.List {
 	return w
 }
 
-func logs(data dashboard, conf config.Configuration) *widgets.Table {
+func logs() *widgets.Table {
 	w := widgets.NewTable()
 
 	w.RowSeparator = false
 	w.BorderStyle.Fg = ui.ColorYellow
 	w.TextStyle = ui.NewStyle(ui.Color(245))
-	w.ColumnWidths = []int{8, 13, 13, 12, 8}
 	w.RowStyles[0] = ui.NewStyle(ui.ColorYellow, ui.ColorBlack, ui.ModifierBold)
 
 	w.Title = "Karma events log"
 
 	w.Rows = [][]string{
-		{"Ago", "From", "To", "Feat", "Karma"},
+		{"Ago", "From", "To", "Feat", "Karma", "Message"},
 	}
 
-	for k, v := range data.Logs {
+	return w
+}
 
-		var feat string
-		for _, f := range conf.Feats {
-			if f.ID == v.FeatID {
-				feat = f.Label
-				break
-			}
-		}
+// logRows returns the rows of the events log and their styles, highlighting the current user's events
+func logRows(data api.Dashboard, conf config.Configuration) ([][]string, []ui.Style) {
+	rows := make([][]string, 0, len(data.Logs))
+	styles := make([]ui.Style, 0, len(data.Logs))
 
-		w.Rows = append(w.Rows, []string{v.Ago, v.From, v.ToUser, feat, fmt.Sprintf("%v", v.Karma)})
+	for _, v := range data.Logs {
+		rows = append(rows, []string{v.Ago, v.From, v.ToUser, featLabel(conf, v.FeatID), fmt.Sprintf("%v", v.Karma), v.Message})
 
 		modifier := ui.ModifierClear
-		color := ui.Color(245)
+		fg := ui.Color(245)
 
 		if v.CurrentUser {
 			modifier = ui.ModifierBold
 			if v.Karma > 0 {
-				color = ui.ColorGreen
+				fg = ui.ColorGreen
 			} else {
-				color = ui.ColorRed
+				fg = ui.ColorRed
 			}
 		}
 
-		w.RowStyles[k+1] = ui.NewStyle(color, ui.ColorClear, modifier)
+		styles = append(styles, ui.NewStyle(fg, ui.ColorClear, modifier))
 	}
 
-	return w
+	return rows, styles
+}
+
+func featLabel(conf config.Configuration, featID string) string {
+	for _, f := range conf.Feats {
+		if f.ID == featID {
+			return f.Label
+		}
+	}
+
+	return ""
 }
 
 func formatNumber(n int64) string {
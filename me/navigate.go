@@ -0,0 +1,165 @@
+package me
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+// Widgets that can get keyboard focus, in Tab order
+const (
+	focusLeaderboard = iota
+	focusFeats
+	focusLogs
+	focusCount
+)
+
+// handle reacts to a key press. It returns the name of a teammate when asked to cheer them.
+func (s *screen) handle(e ui.Event) (cheer string) {
+	if s.details != nil {
+		switch e.ID {
+		case "<Enter>", "<Escape>", "<Backspace>":
+			s.details = nil
+			ui.Clear()
+		}
+		return ""
+	}
+
+	switch e.ID {
+	case "<Tab>":
+		s.focus = (s.focus + 1) % focusCount
+		if s.compact && s.focus == focusFeats {
+			s.focus++
+		}
+		if s.focus == focusFeats {
+			s.touchFeats()
+		}
+	case "j", "<Down>":
+		s.move(1)
+	case "k", "<Up>":
+		s.move(-1)
+	case "l", "<Right>":
+		if s.focus == focusLeaderboard {
+			s.move(1)
+		}
+	case "h", "<Left>":
+		if s.focus == focusLeaderboard {
+			s.move(-1)
+		}
+	case "<Enter>":
+		if s.focus == focusLogs {
+			s.showDetails()
+		}
+	case "c":
+		if s.teammate < len(s.leaderboard.Labels) {
+			return s.leaderboard.Labels[s.teammate]
+		}
+	}
+
+	return ""
+}
+
+// move the selection of the focused widget by delta rows
+func (s *screen) move(delta int) {
+	switch s.focus {
+	case focusLeaderboard:
+		s.teammate = clamp(s.teammate+delta, 0, len(s.leaderboard.Labels)-1)
+	case focusFeats:
+		s.touchFeats()
+		s.feats.ScrollAmount(delta)
+	case focusLogs:
+		s.logSelected = clamp(s.logSelected+delta, 0, len(s.logRows)-1)
+	}
+}
+
+// touchFeats stops the feats list from rotating on its own once the user scrolls it
+func (s *screen) touchFeats() {
+	if s.featsTouched {
+		return
+	}
+
+	s.featsTouched = true
+	s.feats.Rows = s.featsData
+	s.feats.SelectedRow = 0
+}
+
+// applyFocus highlights the focused widget and its selection
+func (s *screen) applyFocus() {
+	s.leaderboard.BorderStyle.Fg = ui.ColorYellow
+	s.feats.BorderStyle.Fg = ui.ColorYellow
+	s.logs.BorderStyle.Fg = ui.ColorYellow
+	s.feats.SelectedRowStyle = s.feats.TextStyle
+
+	switch s.focus {
+	case focusLeaderboard:
+		s.leaderboard.BorderStyle.Fg = ui.ColorCyan
+	case focusFeats:
+		s.feats.BorderStyle.Fg = ui.ColorCyan
+		s.feats.SelectedRowStyle = ui.NewStyle(ui.ColorBlack, ui.ColorCyan)
+	case focusLogs:
+		s.logs.BorderStyle.Fg = ui.ColorCyan
+	}
+
+	s.leaderboard.BarColors = make([]ui.Color, len(s.leaderboard.Labels))
+	for i := range s.leaderboard.BarColors {
+		s.leaderboard.BarColors[i] = ui.ColorBlue
+	}
+	if s.teammate < len(s.leaderboard.BarColors) {
+		s.leaderboard.BarColors[s.teammate] = ui.ColorCyan
+	}
+}
+
+// showLogs fills the events log table with the rows that fit, keeping the selected one visible
+func (s *screen) showLogs() {
+	visible := s.logs.Inner.Dy() - 1
+	if visible < 1 {
+		visible = 1
+	}
+
+	if s.logSelected < s.logOffset {
+		s.logOffset = s.logSelected
+	} else if s.logSelected >= s.logOffset+visible {
+		s.logOffset = s.logSelected - visible + 1
+	}
+
+	s.logs.Rows = s.logs.Rows[:1]
+	s.logs.RowStyles = map[int]ui.Style{0: s.logs.RowStyles[0]}
+
+	for i := s.logOffset; i < len(s.logRows) && i < s.logOffset+visible; i++ {
+		s.logs.Rows = append(s.logs.Rows, s.logRows[i])
+
+		style := s.logStyles[i]
+		if s.focus == focusLogs && i == s.logSelected {
+			style = ui.NewStyle(ui.ColorBlack, ui.ColorCyan, style.Modifier)
+		}
+		s.logs.RowStyles[len(s.logs.Rows)-1] = style
+	}
+}
+
+// showDetails pops over the selected log entry in full
+func (s *screen) showDetails() {
+	if s.logSelected >= len(s.logEntries) {
+		return
+	}
+
+	v := s.logEntries[s.logSelected]
+
+	w := widgets.NewParagraph()
+	w.Title = "Karma event (Enter to close)"
+	w.BorderStyle.Fg = ui.ColorCyan
+	w.Text = fmt.Sprintf("From:    %s\nTo:      %s\nFeat:    %s\nKarma:   %v\nAgo:     %s\nMessage: %s", v.From, v.ToUser, s.logRows[s.logSelected][3], v.Karma, v.Ago, v.Message)
+
+	s.details = w
+}
+
+func clamp(v int, min int, max int) int {
+	if v > max {
+		v = max
+	}
+	if v < min {
+		v = min
+	}
+
+	return v
+}
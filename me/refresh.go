@@ -0,0 +1,154 @@
+package me
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+)
+
+// refresh is the outcome of fetching the dashboard again
+type refresh struct {
+	data api.Dashboard
+	err  error
+	at   time.Time
+}
+
+// poll fetches the dashboard every interval until ctx is done. Calling the returned
+// function fetches it right away, e.g. after a cheer. An interval of 0 only fetches on demand.
+func poll(ctx context.Context, client api.Client, interval time.Duration) (<-chan refresh, func()) {
+	updates := make(chan refresh)
+	now := make(chan struct{}, 1)
+
+	go func() {
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			case <-now:
+			}
+
+			data, err := client.GetDashboard(ctx)
+
+			select {
+			case updates <- refresh{data: data, err: err, at: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates, func() {
+		select {
+		case now <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// newScreen creates the dashboard widgets, to be filled with update
+func newScreen(conf config.Configuration) *screen {
+	featsData := make([]string, 0, len(conf.Feats))
+	for _, f := range conf.Feats {
+		featsData = append(featsData, fmt.Sprintf("%s (%s)", f.Label, f.Slug))
+	}
+
+	return &screen{
+		welcome:     textBox("👋 Welcome", "", true),
+		gauge:       gauge(),
+		multiplier:  textBox("Mul.", "", false),
+		feats:       list("Feats (SLUG)", featsData),
+		tip:         textBox("Tip", "Hit C to cheer the teammate selected on the leaderboard", true),
+		footer:      textBox("Info", "Running karma CLI v1.0.0. Check docs at https://docs.getkarma.dev. Thanks for being awesome 😍.", true),
+		leaderboard: leaderboard(nil, nil),
+		logs:        logs(),
+		conf:        conf,
+		featsData:   featsData,
+		focus:       focusLogs,
+	}
+}
+
+// update shows fresh dashboard data, highlighting log entries that were not there before
+func (s *screen) update(data api.Dashboard, at time.Time) {
+	s.stats = data.User.Stats
+
+	s.welcome.Text = fmt.Sprintf("You are a level %v karma developer.\nYou earned %s karma pts in total.\nTab, arrows, Enter to browse. Q to quit.", data.User.Stats.Level, formatNumber(data.User.TotalAccruedKarma))
+	s.multiplier.Text = fmt.Sprintf(" %vx", data.User.Stats.Multiplier)
+	s.leaderboard.Labels = data.Leaderboard.Names
+	s.leaderboard.Data = data.Leaderboard.Levels
+	s.teammate = clamp(s.teammate, 0, len(data.Leaderboard.Names)-1)
+
+	// entries have no stable identity, so count identical ones to spot additions
+	seen := map[string]int{}
+	for _, v := range s.logEntries {
+		seen[logKey(v)]++
+	}
+	firstLoad := s.logEntries == nil
+
+	s.logEntries = data.Logs
+	s.logRows, s.logStyles = logRows(data, s.conf)
+
+	for i, v := range data.Logs {
+		if seen[logKey(v)] > 0 {
+			seen[logKey(v)]--
+		} else if !firstLoad {
+			s.logStyles[i] = ui.NewStyle(ui.ColorBlack, ui.ColorGreen, ui.ModifierBold)
+		}
+	}
+
+	s.logSelected = clamp(s.logSelected, 0, len(s.logRows)-1)
+	s.logs.Title = fmt.Sprintf("Karma events log · updated %s", at.Format("15:04"))
+}
+
+// setStale shows a banner when the data shown is a cached copy, or removes it when since is zero
+func (s *screen) setStale(since time.Time) {
+	if since.IsZero() {
+		s.welcome.Title = "👋 Welcome"
+		s.welcome.TitleStyle = ui.Theme.Block.Title
+		return
+	}
+
+	s.welcome.Title = fmt.Sprintf("⚠ Stale since %s, API unreachable", since.Format("Jan 2 15:04"))
+	s.welcome.TitleStyle = ui.NewStyle(ui.ColorRed, ui.ColorClear, ui.ModifierBold)
+}
+
+func logKey(v api.LogEntry) string {
+	if v.ID != "" {
+		return v.ID
+	}
+
+	return strings.Join([]string{v.From, v.ToUser, v.FeatID, fmt.Sprint(v.Karma), v.Message}, "\x00")
+}
+
+// tick animates the gauge towards the current progress and rotates the feats list
+func (s *screen) tick(count int) {
+	target := s.stats.Progress
+
+	if s.gauge.Percent < target {
+		s.gauge.Percent++
+		s.gauge.Label = fmt.Sprintf("%v%%", s.gauge.Percent)
+	} else if s.gauge.Percent > target {
+		s.gauge.Percent--
+		s.gauge.Label = fmt.Sprintf("%v%%", s.gauge.Percent)
+	} else {
+		s.gauge.Label = fmt.Sprintf("%v%% - %s pts to lvl %v", target, formatNumber(s.stats.KarmaToNextLevel), s.stats.Level+1)
+	}
+
+	if count%20 == 0 && len(s.featsData) > 0 && !s.featsTouched {
+		s.feats.Rows = s.featsData[(count/20)%(len(s.featsData)):]
+	}
+
+	s.render()
+}
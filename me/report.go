@@ -0,0 +1,68 @@
+package me
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+)
+
+// progressBarWidth is the number of characters of the text progress bar
+const progressBarWidth = 30
+
+// printReport writes the dashboard as static text, for pipes, CI logs and screen readers
+func printReport(w io.Writer, data api.Dashboard, conf config.Configuration) error {
+	stats := data.User.Stats
+
+	fmt.Fprintf(w, "Level:       %v\n", stats.Level)
+	fmt.Fprintf(w, "Multiplier:  %vx\n", stats.Multiplier)
+	fmt.Fprintf(w, "Total karma: %s pts\n", formatNumber(data.User.TotalAccruedKarma))
+	fmt.Fprintf(w, "Progress:    %s %v%% - %s pts to level %v\n", progressBar(stats.Progress), stats.Progress, formatNumber(stats.KarmaToNextLevel), stats.Level+1)
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Team leaderboard")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tNAME\tLEVEL")
+	for i, name := range data.Leaderboard.Names {
+		var level float64
+		if i < len(data.Leaderboard.Levels) {
+			level = data.Leaderboard.Levels[i]
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%v\n", i+1, name, level)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Karma events log")
+
+	if len(data.Logs) == 0 {
+		fmt.Fprintln(w, "No events yet.")
+		return nil
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGO\tFROM\tTO\tFEAT\tKARMA\tMESSAGE")
+	for _, v := range data.Logs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%v\t%s\n", v.Ago, v.From, v.ToUser, featLabel(conf, v.FeatID), v.Karma, v.Message)
+	}
+
+	return tw.Flush()
+}
+
+func progressBar(percent int) string {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+
+	filled := percent * progressBarWidth / 100
+
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled) + "]"
+}
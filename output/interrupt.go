@@ -0,0 +1,19 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+)
+
+// HandleInterrupt turns Ctrl-C in a prompt into a quiet exit, and returns other errors as is
+func HandleInterrupt(err error) error {
+	if err == terminal.InterruptErr {
+		fmt.Println("interrupted")
+
+		os.Exit(0)
+	}
+
+	return err
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+// Format is how a command prints its results
+type Format string
+
+const (
+	// Table is the default, human-friendly format
+	Table Format = "table"
+	// JSON prints results as indented JSON
+	JSON Format = "json"
+	// YAML prints results as YAML
+	YAML Format = "yaml"
+)
+
+// AddFlag registers the --output flag on a flag set
+func AddFlag(flags *pflag.FlagSet) {
+	flags.String("output", string(Table), "output format: table, json or yaml")
+}
+
+// FromFlags returns the format selected with --output
+func FromFlags(flags *pflag.FlagSet) (Format, error) {
+	value, _ := flags.GetString("output")
+
+	switch f := Format(value); f {
+	case "", Table:
+		return Table, nil
+	case JSON, YAML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, use table, json or yaml", value)
+	}
+}
+
+// Structured tells whether results should be printed as data rather than prose
+func (f Format) Structured() bool {
+	return f == JSON || f == YAML
+}
+
+// Print writes v in a structured format, using its JSON field names for YAML as well
+func Print(w io.Writer, f Format, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if f == YAML {
+		// JSON is valid YAML, decoding it into map slices keeps the field order
+		var doc interface{} = &yaml.MapSlice{}
+		if content[0] == '[' {
+			doc = &[]yaml.MapSlice{}
+		}
+
+		if err := yaml.Unmarshal(content, doc); err != nil {
+			return err
+		}
+
+		if content, err = yaml.Marshal(doc); err != nil {
+			return err
+		}
+	} else {
+		content = append(content, '\n')
+	}
+
+	_, err = w.Write(content)
+
+	return err
+}
@@ -0,0 +1,243 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/spf13/cobra"
+)
+
+// profileOutput is the structured description of a profile
+type profileOutput struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	User   string `json:"user"`
+	Team   string `json:"team"`
+	Host   string `json:"host"`
+}
+
+// NewCmdProfile creates a profile command
+func NewCmdProfile() *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "profile",
+		Short: "Manage configuration profiles",
+		Long: heredoc.Doc(`
+			Manage configuration profiles.
+
+			Each profile has its own login, team and API host, so you can switch between
+			GitHub organizations without configuring them again. The default profile is
+			stored in ~/.karma.yaml and others in ~/.karma.<name>.yaml.
+
+			The active profile is picked from the --profile flag, then the KARMA_PROFILE
+			environment variable, then 'karma profile use'.
+		`),
+		Example: heredoc.Doc(`
+			# setup a profile for another org, then switch back and forth
+			$ karma profile add work --use
+			$ karma login xxx
+			$ karma config --org my-company
+			$ karma profile use default
+
+			# cheer from the work profile once
+			$ karma c alice --profile work
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return listRun(format)
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	addCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Create a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, _ := cmd.Flags().GetString("host")
+			use, _ := cmd.Flags().GetBool("use")
+
+			return addRun(args[0], host, use)
+		},
+	}
+	addCmd.Flags().String("host", "", "Karma API host for this profile")
+	addCmd.Flags().Bool("use", false, "make the new profile the active one")
+	cmd.AddCommand(addCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "use <name>",
+		Short: "Make a profile the active one",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return useRun(args[0])
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return listRun(format)
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove <name>",
+		Short:   "Delete a profile and its local data",
+		Aliases: []string{"rm"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return removeRun(args[0])
+		},
+	})
+
+	return cmd
+}
+
+func addRun(name string, host string, use bool) error {
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	if name == config.DefaultProfile {
+		return fmt.Errorf("%q is reserved for ~/.karma.yaml", name)
+	}
+
+	if err := config.CreateProfile(name, host); err != nil {
+		return err
+	}
+
+	color.Green(fmt.Sprintf("✅ Created profile %s.", name))
+
+	if use {
+		return useRun(name)
+	}
+
+	color.Yellow(fmt.Sprintf("👉 Run 'karma profile use %s' to switch to it, or pass --profile %s.", name, name))
+
+	return nil
+}
+
+func useRun(name string) error {
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	if name != config.DefaultProfile && !config.ProfileExists(name) {
+		return fmt.Errorf("no profile named %q, create it with 'karma profile add %s'", name, name)
+	}
+
+	if err := config.UseProfile(name); err != nil {
+		return err
+	}
+
+	color.Green(fmt.Sprintf("✅ Now using profile %s.", name))
+
+	if os.Getenv("KARMA_PROFILE") != "" && os.Getenv("KARMA_PROFILE") != name {
+		color.Yellow(fmt.Sprintf("Heads up! KARMA_PROFILE is set to %s and takes precedence.", os.Getenv("KARMA_PROFILE")))
+	}
+
+	return nil
+}
+
+func listRun(format output.Format) error {
+	names, err := config.Profiles()
+	if err != nil {
+		return err
+	}
+
+	active := config.Profile()
+
+	profiles := []profileOutput{}
+	for _, name := range names {
+		conf, err := config.ReadProfile(name)
+		if err != nil {
+			return err
+		}
+
+		host := conf.Host
+		if host == "" {
+			host = config.DefaultHost
+		}
+
+		profiles = append(profiles, profileOutput{
+			Name:   name,
+			Active: name == active,
+			User:   conf.User.Name,
+			Team:   conf.Team.Name,
+			Host:   host,
+		})
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, profiles)
+	}
+
+	if len(profiles) == 0 {
+		fmt.Println("No profiles yet, run 'karma login <token>' to create the default one.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tUSER\tTEAM\tHOST")
+	for _, p := range profiles {
+		marker := ""
+		if p.Active {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", marker, p.Name, p.User, p.Team, p.Host)
+	}
+
+	return w.Flush()
+}
+
+func removeRun(name string) error {
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	if name == config.DefaultProfile {
+		return fmt.Errorf("the default profile cannot be removed")
+	}
+
+	if err := config.RemoveProfile(name); err != nil {
+		return err
+	}
+
+	color.Green(fmt.Sprintf("✅ Removed profile %s.", name))
+
+	return nil
+}
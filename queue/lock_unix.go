@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package queue
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockFile takes an exclusive lock on f, released when f is closed
+func lockFile(f *os.File, wait bool) error {
+	how := unix.LOCK_EX
+	if !wait {
+		how |= unix.LOCK_NB
+	}
+
+	err := unix.Flock(int(f.Fd()), how)
+	if err == unix.EWOULDBLOCK {
+		return errLocked
+	}
+
+	return err
+}
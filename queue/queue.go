@@ -0,0 +1,266 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdQueue creates a queue command
+func NewCmdQueue(client *api.Client) *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "queue",
+		Short: "Manage cheers waiting to be sent",
+		Long: heredoc.Doc(`
+			Manage cheers waiting to be sent.
+
+			Cheers that cannot reach the Karma API, e.g. when you are offline, are queued
+			and sent automatically after the next successful command.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return listRun(format)
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List queued cheers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return listRun(format)
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "flush",
+		Short: "Send queued cheers now",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return flushRun(cmd.Context(), *client, format)
+		},
+	})
+
+	dropCmd := &cobra.Command{
+		Use:   "drop [<id>...]",
+		Short: "Remove queued cheers without sending them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return dropRun(args, all, format)
+		},
+	}
+	dropCmd.Flags().Bool("all", false, "drop all queued cheers")
+	cmd.AddCommand(dropCmd)
+
+	return cmd
+}
+
+// enqueued tells whether a cheer was queued during this run, in which case there is no point flushing
+var enqueued bool
+
+// Enqueue saves a cheer that could not be sent
+func Enqueue(cheer api.Cheer, recipient string, feat string, cause error) (Entry, error) {
+	q, err := Open(config.QueuePath(), true)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer q.Close()
+
+	e := q.Add(cheer, recipient, feat, cause)
+	enqueued = true
+
+	return e, q.Save()
+}
+
+// AutoFlush tries to send queued cheers, reporting only what was sent.
+// It is meant to run quietly after commands that reached the API.
+func AutoFlush(ctx context.Context, client api.Client) {
+	if enqueued {
+		return
+	}
+
+	// another process is already sending queued cheers
+	q, err := Open(config.QueuePath(), false)
+	if err != nil {
+		return
+	}
+	defer q.Close()
+
+	if len(q.Entries) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	sent, _ := q.Flush(ctx, client)
+	if len(sent) == 0 {
+		return
+	}
+
+	if err := q.Save(); err != nil {
+		return
+	}
+
+	color.New(color.FgGreen).Fprintln(os.Stderr, fmt.Sprintf("📬 Sent %d queued cheer(s), %d left in queue.", len(sent), len(q.Entries)))
+}
+
+func listRun(format output.Format) error {
+	q, err := Load(config.QueuePath())
+	if err != nil {
+		return err
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, append([]Entry{}, q.Entries...))
+	}
+
+	if len(q.Entries) == 0 {
+		fmt.Println("No queued cheers.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTO\tFEAT\tMESSAGE\tQUEUED\tLAST ERROR")
+	for _, e := range q.Entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.ID, e.Recipient, e.Feat, e.Cheer.Msg, e.QueuedAt.Format("Jan 2 15:04"), e.LastError)
+	}
+
+	return w.Flush()
+}
+
+func flushRun(ctx context.Context, client api.Client, format output.Format) error {
+	q, err := Open(config.QueuePath(), true)
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	if len(q.Entries) == 0 && !format.Structured() {
+		fmt.Println("No queued cheers.")
+		return nil
+	}
+
+	sent, flushErr := q.Flush(ctx, client)
+
+	if err := q.Save(); err != nil {
+		return err
+	}
+
+	if format.Structured() {
+		if err := output.Print(os.Stdout, format, struct {
+			Sent   []Entry `json:"sent"`
+			Queued []Entry `json:"queued"`
+		}{append([]Entry{}, sent...), append([]Entry{}, q.Entries...)}); err != nil {
+			return err
+		}
+
+		if flushErr != nil {
+			return fmt.Errorf("%d cheer(s) still queued: %w", len(q.Entries), flushErr)
+		}
+
+		return nil
+	}
+
+	for _, e := range sent {
+		color.Green(fmt.Sprintf("✅ Sent cheer to %s for %s.", e.Recipient, e.Feat))
+	}
+
+	if flushErr != nil {
+		return fmt.Errorf("%d cheer(s) still queued: %w", len(q.Entries), flushErr)
+	}
+
+	if len(q.Entries) > 0 {
+		color.Yellow(fmt.Sprintf("%d cheer(s) were rejected by the API, see 'karma queue list' and drop them with 'karma queue drop <id>'.", len(q.Entries)))
+	}
+
+	return nil
+}
+
+func dropRun(ids []string, all bool, format output.Format) error {
+	if len(ids) == 0 && !all {
+		return errors.New("specify the IDs of the cheers to drop, or --all")
+	}
+
+	q, err := Open(config.QueuePath(), true)
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	var dropped int
+	if all {
+		dropped = len(q.Entries)
+		q.Entries = nil
+	} else {
+		dropped = q.Drop(ids...)
+	}
+
+	if err := q.Save(); err != nil {
+		return err
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, struct {
+			Dropped int `json:"dropped"`
+		}{dropped})
+	}
+
+	fmt.Printf("Dropped %d queued cheer(s).\n", dropped)
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/mitchellh/go-homedir"
+)
+
+// setup points the configuration to a temporary home
+func setup(t *testing.T) {
+	homedir.DisableCache = true
+
+	for name, value := range map[string]string{"HOME": t.TempDir(), "KARMA_PROFILE": ""} {
+		previous, set := os.LookupEnv(name)
+		if value == "" {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, value)
+		}
+
+		name := name
+		t.Cleanup(func() {
+			if set {
+				os.Setenv(name, previous)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+
+	t.Cleanup(func() { enqueued = false })
+}
+
+func TestEnqueuePerProfile(t *testing.T) {
+	setup(t)
+
+	if _, err := Enqueue(api.Cheer{UserID: "u2", Msg: "ok"}, "Alice", "pairing", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	config.SetProfile("work")
+	defer config.SetProfile("")
+
+	if _, err := Enqueue(api.Cheer{UserID: "u3", Msg: "ok"}, "Bob", "pairing", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	work, err := Load(config.QueuePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.SetProfile("")
+	def, err := Load(config.QueuePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(def.Entries) != 1 || def.Entries[0].Recipient != "Alice" {
+		t.Errorf("default queue holds %+v, want Alice's cheer", def.Entries)
+	}
+	if len(work.Entries) != 1 || work.Entries[0].Recipient != "Bob" {
+		t.Errorf("work queue holds %+v, want Bob's cheer", work.Entries)
+	}
+}
+
+func TestEnqueueConcurrently(t *testing.T) {
+	setup(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Enqueue(api.Cheer{UserID: "u2", Msg: "ok"}, "Alice", "pairing", nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	q, err := Load(config.QueuePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Entries) != 20 {
+		t.Errorf("queue holds %d entries, want 20", len(q.Entries))
+	}
+}
+
+func TestAutoFlush(t *testing.T) {
+	setup(t)
+	stub, client := newStubAPI(t)
+
+	if _, err := Enqueue(api.Cheer{UserID: "u2", Msg: "ok"}, "Alice", "pairing", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is flushed in the run that queued a cheer
+	AutoFlush(context.Background(), client)
+	if len(stub.keys["ok"]) != 0 {
+		t.Error("AutoFlush() sent a cheer queued during the same run")
+	}
+	enqueued = false
+
+	// nor while another process is flushing
+	q, err := Open(config.QueuePath(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AutoFlush(context.Background(), client)
+	q.Close()
+	if len(stub.keys["ok"]) != 0 {
+		t.Error("AutoFlush() sent a cheer while the queue was locked")
+	}
+
+	AutoFlush(context.Background(), client)
+	if len(stub.keys["ok"]) != 1 {
+		t.Errorf("AutoFlush() sent %d cheer(s), want 1", len(stub.keys["ok"]))
+	}
+
+	if q, _ := Load(config.QueuePath()); len(q.Entries) != 0 {
+		t.Errorf("queue holds %d entries after AutoFlush(), want 0", len(q.Entries))
+	}
+}
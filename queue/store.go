@@ -0,0 +1,182 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/krmdv/cli/api"
+)
+
+// Entry is a cheer waiting to be sent
+type Entry struct {
+	ID             string    `json:"id"`
+	IdempotencyKey string    `json:"idempotencyKey"`
+	Cheer          api.Cheer `json:"cheer"`
+	Recipient      string    `json:"recipient"`
+	Feat           string    `json:"feat"`
+	QueuedAt       time.Time `json:"queuedAt"`
+	Attempts       int       `json:"attempts"`
+	LastError      string    `json:"lastError,omitempty"`
+}
+
+// Queue holds cheers that could not be sent, persisted to a file
+type Queue struct {
+	path    string
+	lock    *os.File
+	Entries []Entry
+}
+
+// errLocked is returned when another process is changing the queue and the caller doesn't wait
+var errLocked = errors.New("the queue is being changed by another karma process")
+
+// Open locks the queue file at path and reads it, so that processes adding or flushing cheers at
+// the same time neither lose entries nor send them twice. Unless wait is set, it fails with
+// errLocked if another process holds the lock. The lock is released by Close.
+func Open(path string, wait bool) (*Queue, error) {
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(lock, wait); err != nil {
+		lock.Close()
+		return nil, err
+	}
+
+	q, err := Load(path)
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+
+	q.lock = lock
+
+	return q, nil
+}
+
+// Close releases the lock taken by Open
+func (q *Queue) Close() error {
+	if q.lock == nil {
+		return nil
+	}
+
+	err := q.lock.Close()
+	q.lock = nil
+
+	return err
+}
+
+// Load reads the queue file at path without locking it, returning an empty queue if it does not
+// exist. Use Open to change the queue.
+func Load(path string) (*Queue, error) {
+	q := &Queue{path: path}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &q.Entries); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// Save writes the queue back to its file, removing it when empty
+func (q *Queue) Save() error {
+	if len(q.Entries) == 0 {
+		if err := os.Remove(q.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	content, err := json.MarshalIndent(q.Entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(q.path, content, 0600)
+}
+
+// Add queues a cheer, keeping its idempotency key so it is never awarded twice
+func (q *Queue) Add(cheer api.Cheer, recipient string, feat string, cause error) Entry {
+	if cheer.IdempotencyKey == "" {
+		cheer.IdempotencyKey = api.NewIdempotencyKey()
+	}
+
+	e := Entry{
+		ID:             cheer.IdempotencyKey[:8],
+		IdempotencyKey: cheer.IdempotencyKey,
+		Cheer:          cheer,
+		Recipient:      recipient,
+		Feat:           feat,
+		QueuedAt:       time.Now(),
+	}
+
+	if cause != nil {
+		e.LastError = cause.Error()
+	}
+
+	q.Entries = append(q.Entries, e)
+
+	return e
+}
+
+// Drop removes the entries with the given IDs and returns how many were removed
+func (q *Queue) Drop(ids ...string) int {
+	drop := map[string]bool{}
+	for _, id := range ids {
+		drop[id] = true
+	}
+
+	kept := q.Entries[:0]
+	for _, e := range q.Entries {
+		if !drop[e.ID] {
+			kept = append(kept, e)
+		}
+	}
+
+	dropped := len(q.Entries) - len(kept)
+	q.Entries = kept
+
+	return dropped
+}
+
+// Flush sends queued cheers in order. Sent entries are removed from the queue; it stops at
+// the first temporary failure, since the following ones would most likely fail too.
+func (q *Queue) Flush(ctx context.Context, client api.Client) (sent []Entry, err error) {
+	var kept []Entry
+
+	for i, e := range q.Entries {
+		cheer := e.Cheer
+		cheer.IdempotencyKey = e.IdempotencyKey
+
+		_, sendErr := client.CreateCheer(ctx, cheer)
+		if sendErr == nil {
+			sent = append(sent, e)
+			continue
+		}
+
+		e.Attempts++
+		e.LastError = sendErr.Error()
+		kept = append(kept, e)
+
+		if api.IsTemporary(sendErr) || ctx.Err() != nil {
+			kept = append(kept, q.Entries[i+1:]...)
+			err = sendErr
+			break
+		}
+	}
+
+	q.Entries = kept
+
+	return sent, err
+}
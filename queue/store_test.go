@@ -0,0 +1,230 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/krmdv/cli/api"
+)
+
+// stubAPI answers cheers according to their message: "ok" is accepted, "rejected" is refused for
+// good and "down" fails temporarily. It records the idempotency keys it sees, by message.
+type stubAPI struct {
+	mu   sync.Mutex
+	keys map[string][]string
+}
+
+func (s *stubAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var cheer api.Cheer
+	json.NewDecoder(r.Body).Decode(&cheer)
+
+	s.mu.Lock()
+	s.keys[cheer.Msg] = append(s.keys[cheer.Msg], r.Header.Get("Idempotency-Key"))
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch cheer.Msg {
+	case "ok":
+		w.Write([]byte(`{"karma": 10}`))
+	case "rejected":
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message": "unknown feat"}`))
+	default:
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"message": "down for maintenance"}`))
+	}
+}
+
+func newStubAPI(t *testing.T) (*stubAPI, api.Client) {
+	stub := &stubAPI{keys: map[string][]string{}}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	return stub, api.NewClient(srv.URL, "token", "t1", "test", api.WithRetries(0))
+}
+
+func messages(entries []Entry) []string {
+	var msgs []string
+	for _, e := range entries {
+		msgs = append(msgs, e.Cheer.Msg)
+	}
+
+	return msgs
+}
+
+func TestAdd(t *testing.T) {
+	q := &Queue{}
+
+	given := q.Add(api.Cheer{UserID: "u2", Msg: "ok", IdempotencyKey: "0123456789abcdef"}, "Alice", "pairing", errors.New("timeout"))
+	if given.IdempotencyKey != "0123456789abcdef" || given.ID != "01234567" || given.LastError != "timeout" {
+		t.Errorf("Add() = %+v, want the cheer's idempotency key kept", given)
+	}
+
+	generated := q.Add(api.Cheer{UserID: "u3", Msg: "ok"}, "Bob", "pairing", nil)
+	if generated.IdempotencyKey == "" || generated.ID != generated.IdempotencyKey[:8] {
+		t.Errorf("Add() = %+v, want an idempotency key generated", generated)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name   string
+		queued []string
+		sent   []string
+		kept   []string
+		// attempts are those of the kept entries, only entries the API answered count one
+		attempts []int
+		err      bool
+	}{
+		{name: "all sent", queued: []string{"ok", "ok"}, sent: []string{"ok", "ok"}},
+		{
+			name:     "permanent errors are kept and skipped",
+			queued:   []string{"rejected", "ok"},
+			sent:     []string{"ok"},
+			kept:     []string{"rejected"},
+			attempts: []int{1},
+		},
+		{
+			name:     "temporary errors stop the flush",
+			queued:   []string{"ok", "down", "ok"},
+			sent:     []string{"ok"},
+			kept:     []string{"down", "ok"},
+			attempts: []int{1, 0},
+			err:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newStubAPI(t)
+
+			q := &Queue{}
+			for _, msg := range tt.queued {
+				q.Add(api.Cheer{UserID: "u2", FeatID: "f1", Msg: msg}, "Alice", "pairing", nil)
+			}
+
+			sent, err := q.Flush(context.Background(), client)
+			if (err != nil) != tt.err {
+				t.Errorf("Flush() error = %v, want error %v", err, tt.err)
+			}
+			if got := messages(sent); !reflect.DeepEqual(got, tt.sent) {
+				t.Errorf("Flush() sent %v, want %v", got, tt.sent)
+			}
+			if got := messages(q.Entries); !reflect.DeepEqual(got, tt.kept) {
+				t.Errorf("Flush() kept %v, want %v", got, tt.kept)
+			}
+
+			var attempts []int
+			for _, e := range q.Entries {
+				attempts = append(attempts, e.Attempts)
+			}
+			if !reflect.DeepEqual(attempts, tt.attempts) {
+				t.Errorf("Flush() left attempts %v, want %v", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestFlushReusesIdempotencyKeys(t *testing.T) {
+	stub, client := newStubAPI(t)
+	path := filepath.Join(t.TempDir(), "queue.json")
+
+	q, err := Open(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := q.Add(api.Cheer{UserID: "u2", FeatID: "f1", Msg: "down"}, "Alice", "pairing", nil)
+	if err := q.Save(); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	// each flush reads the queue again, as separate runs would
+	for i := 0; i < 2; i++ {
+		q, err := Open(path, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := q.Flush(context.Background(), client); err == nil {
+			t.Error("Flush() succeeded while the API is down")
+		}
+		if err := q.Save(); err != nil {
+			t.Fatal(err)
+		}
+		q.Close()
+	}
+
+	if keys := stub.keys["down"]; !reflect.DeepEqual(keys, []string{e.IdempotencyKey, e.IdempotencyKey}) {
+		t.Errorf("API got idempotency keys %v, want %s twice", keys, e.IdempotencyKey)
+	}
+
+	q, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Entries) != 1 || q.Entries[0].Attempts != 2 {
+		t.Errorf("queue holds %+v, want the cheer with 2 attempts", q.Entries)
+	}
+}
+
+func TestSaveEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+
+	q := &Queue{path: path}
+	q.Add(api.Cheer{UserID: "u2", Msg: "ok"}, "Alice", "pairing", nil)
+	if err := q.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	q.Entries = nil
+	if err := q.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("an empty queue should remove its file, got %v", err)
+	}
+}
+
+func TestOpenLocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+
+	q, err := Open(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path, false); !errors.Is(err, errLocked) {
+		t.Errorf("Open() of a locked queue = %v, want errLocked", err)
+	}
+
+	opened := make(chan *Queue)
+	go func() {
+		other, err := Open(path, true)
+		if err != nil {
+			t.Error(err)
+		}
+		opened <- other
+	}()
+
+	q.Add(api.Cheer{UserID: "u2", Msg: "ok"}, "Alice", "pairing", nil)
+	if err := q.Save(); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	// the waiting process sees the changes made while it waited
+	other := <-opened
+	defer other.Close()
+
+	if len(other.Entries) != 1 {
+		t.Errorf("queue opened after a change holds %d entries, want 1", len(other.Entries))
+	}
+}
@@ -17,20 +17,35 @@ limitations under the License.
 package setup
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"github.com/krmdv/cli/api"
 	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// setupOutput is the structured result of the config command
+type setupOutput struct {
+	Team struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"team"`
+	Users            []api.User `json:"users"`
+	Feats            []api.Feat `json:"feats"`
+	GithubWebhookURL string     `json:"githubWebhookUrl,omitempty"`
+	SentryWebhookURL string     `json:"sentryWebhookUrl,omitempty"`
+}
+
 // NewCmdSetup creates a cheer command
-func NewCmdSetup(client api.Client) *cobra.Command {
+func NewCmdSetup(client *api.Client) *cobra.Command {
 
 	var cmd = &cobra.Command{
 		Use:   "config",
@@ -45,7 +60,13 @@ func NewCmdSetup(client api.Client) *cobra.Command {
 			slackWebhookURL, _ := cmd.Flags().GetString("slack")
 			printGithub, _ := cmd.Flags().GetBool("github")
 			printSentry, _ := cmd.Flags().GetBool("sentry")
-			return setupRun(client, org, slackWebhookURL, printGithub, printSentry)
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return setupRun(cmd.Context(), *client, org, slackWebhookURL, printGithub, printSentry, format)
 		},
 	}
 
@@ -58,70 +79,57 @@ func NewCmdSetup(client api.Client) *cobra.Command {
 	return cmd
 }
 
-func setupRun(client api.Client, org string, slackWebhookURL string, printGithub bool, printSentry bool) error {
+func setupRun(ctx context.Context, client api.Client, org string, slackWebhookURL string, printGithub bool, printSentry bool, format output.Format) error {
 	s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
 
-	s.Start()
+	if !format.Structured() {
+		s.Start()
+		defer s.Stop()
+	}
 
 	if org != "" {
-		type setTeamPayload struct {
-			GithubLogin string `json:"githubLogin"`
-		}
-
-		type setTeamResp struct {
-			ID    string `json:"id"`
-			Token string `json:"apiToken"`
-			Name  string `json:"name"`
-			Users []struct {
-				ID   string `json:"id"`
-				Name string `json:"name"`
-			} `json:"users"`
+		// Get team configuration (id and members)
+		team, err := client.SetTeam(ctx, org)
+		if err != nil {
+			return err
 		}
 
-		type getFeatsResp []struct {
-			ID    string `json:"id"`
-			Label string `json:"label"`
-			Karma int    `json:"karma"`
-			Slug  string `json:"slug"`
+		feats, err := client.ListFeats(ctx)
+		if err != nil {
+			return err
 		}
 
-		var teamResp setTeamResp
-		var featsResp getFeatsResp
+		config.Set("team.id", team.ID)
 
-		// Get team configuration (id and members)
-		if err := client.Post("/teams", setTeamPayload{GithubLogin: org}, &teamResp); err != nil {
-			return err
+		if err := config.SetSecret("team.token", team.Token); err != nil {
+			return fmt.Errorf("could not save the team token: %w", err)
 		}
 
-		if err := client.Get("/feats", &featsResp); err != nil {
+		if err := config.SaveTeamCache(config.TeamCache{Team: team, Feats: feats}); err != nil {
 			return err
 		}
-
-		viper.Set("team.id", teamResp.ID)
-		viper.Set("team.token", teamResp.Token)
-		viper.Set("team.name", teamResp.Name)
-		viper.Set("users", teamResp.Users)
-		viper.Set("feats", featsResp)
-
-		viper.WriteConfig()
 	}
 
 	if slackWebhookURL != "" {
-		type setSlackWebhookURLPayload struct {
-			SlackWebhookURL string `json:"slackWebhookUrl"`
-		}
-
-		if err := client.Post("/teams/current/slack-webhook-url", setSlackWebhookURLPayload{SlackWebhookURL: slackWebhookURL}, `{}`); err != nil {
+		if err := client.SetSlackWebhookURL(ctx, slackWebhookURL); err != nil {
 			return err
 		}
 	}
 
-	if printGithub {
+	teamToken, err := config.Secret("team.token")
+	if err != nil && (printGithub || printSentry) {
+		return fmt.Errorf("could not read the team token, run 'karma config --org xxx' again: %w", err)
+	}
+
+	githubWebhookURL := "https://api.getkarma.dev/events/github?token=" + teamToken
+	sentryWebhookURL := "https://api.getkarma.dev/events/sentry?token=" + teamToken
+
+	if printGithub && !format.Structured() {
 		fmt.Print("👉 Navigate to the following link: ")
 		color.Yellow("https://github.com/organizations/%s/settings/hooks/new", viper.GetString("team.name"))
 		fmt.Println()
 		fmt.Print("* Set the 'Payload URL' field to: ")
-		color.Blue("https://api.getkarma.dev/events/github?token=" + viper.GetString("team.token"))
+		color.Blue(githubWebhookURL)
 		fmt.Print("* Set 'Content type' field to: ")
 		color.Blue("application/json")
 		fmt.Println("* Leave 'Secret' field blank.")
@@ -131,13 +139,13 @@ func setupRun(client api.Client, org string, slackWebhookURL string, printGithub
 		fmt.Println("Then save changes.")
 	}
 
-	if printSentry {
+	if printSentry && !format.Structured() {
 		fmt.Print("👉 Navigate to the following link: ")
 		color.Yellow("https://sentry.io/settings/%s/developer-settings/new-internal/", viper.GetString("team.name"))
 		fmt.Println("(you might need to change this URL to reflect your Sentry org name if it differs from Github's)")
 		fmt.Println()
 		fmt.Print("* Set the 'Webhook URL' field to: ")
-		color.Blue("https://api.getkarma.dev/events/sentry?token=" + viper.GetString("team.token"))
+		color.Blue(sentryWebhookURL)
 		fmt.Print("* Set 'Issues & Events' permission field to: ")
 		color.Blue("Read")
 		fmt.Print("* Select the following check in the 'Webhooks' box: ")
@@ -146,12 +154,30 @@ func setupRun(client api.Client, org string, slackWebhookURL string, printGithub
 		fmt.Println("Then save changes.")
 	}
 
-	if err := client.Post("/users/me/setup", `{}`, `{}`); err != nil {
+	if err := client.CompleteSetup(ctx); err != nil {
 		return err
 	}
 
 	s.Stop()
 
+	if format.Structured() {
+		conf := config.Get()
+
+		var out setupOutput
+		out.Team.ID = conf.Team.ID
+		out.Team.Name = conf.Team.Name
+		out.Users = conf.Users
+		out.Feats = conf.Feats
+		if printGithub {
+			out.GithubWebhookURL = githubWebhookURL
+		}
+		if printSentry {
+			out.SentryWebhookURL = sentryWebhookURL
+		}
+
+		return output.Print(os.Stdout, format, out)
+	}
+
 	color.Green("✅ All set! You're ready to spread good karma.")
 
 	return nil
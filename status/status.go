@@ -0,0 +1,268 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/spf13/cobra"
+)
+
+// statusOutput is the structured status report
+type statusOutput struct {
+	Profile    string `json:"profile"`
+	ConfigPath string `json:"configPath"`
+	Host       string `json:"host"`
+	User       string `json:"user"`
+	Team       struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"team"`
+	Token struct {
+		Source string `json:"source"`
+		Valid  bool   `json:"valid"`
+		Error  string `json:"error,omitempty"`
+	} `json:"token"`
+	Cache struct {
+		Users    int        `json:"users"`
+		Feats    int        `json:"feats"`
+		SyncedAt *time.Time `json:"syncedAt"`
+		Stale    bool       `json:"stale"`
+	} `json:"cache"`
+	Version struct {
+		CLI        string `json:"cli"`
+		API        string `json:"api,omitempty"`
+		MinCLI     string `json:"minCli,omitempty"`
+		LatestCLI  string `json:"latestCli,omitempty"`
+		Compatible bool   `json:"compatible"`
+	} `json:"version"`
+	Problems []string `json:"problems"`
+
+	// tokenErrorKind tells a rejected token from an unreachable API
+	tokenErrorKind api.ErrorKind
+}
+
+// NewCmdStatus creates a status command
+func NewCmdStatus(client *api.Client, conf *config.Configuration) *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:     "status",
+		Short:   "Check your login, team and API access",
+		Aliases: []string{"whoami"},
+		Long: heredoc.Doc(`
+			Check your login, team and API access.
+
+			Reports the signed in user, active team, API host, whether the token is
+			accepted by the API, the users and feats cached by 'karma config --org' and
+			whether this version of the CLI is supported.
+
+			Exits with a non-zero status when something needs fixing, so it can be used
+			as a health check in scripts.
+		`),
+		Example: heredoc.Doc(`
+			$ karma status
+			$ karma status --output json | jq .problems
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return statusRun(cmd.Context(), *client, *conf, format)
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func statusRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format) error {
+	var out statusOutput
+
+	out.Profile = config.Profile()
+	out.ConfigPath = config.Path()
+	out.Host = client.Host()
+	out.User = conf.User.Name
+	out.Team.ID = conf.Team.ID
+	out.Team.Name = conf.Team.Name
+	out.Token.Source = config.TokenSource()
+	out.Cache.Users = len(conf.Users)
+	out.Cache.Feats = len(conf.Feats)
+	if syncedAt := config.SyncedAt(); !syncedAt.IsZero() {
+		out.Cache.SyncedAt = &syncedAt
+		out.Cache.Stale = config.CacheStale()
+	}
+	out.Version.CLI = client.Version()
+	out.Problems = []string{}
+
+	if conf.Token == "" {
+		out.Problems = append(out.Problems, "not logged in, run 'karma login'")
+	} else if me, err := client.GetMe(ctx); err != nil {
+		out.Token.Error = err.Error()
+		out.tokenErrorKind = api.Classify(err)
+		if hint := out.tokenErrorKind.Hint(); hint != "" {
+			out.Problems = append(out.Problems, hint)
+		} else {
+			out.Problems = append(out.Problems, err.Error())
+		}
+	} else {
+		out.Token.Valid = true
+		out.User = me.Name
+	}
+
+	if conf.Team.ID == "" {
+		out.Problems = append(out.Problems, "no team set up, run 'karma config --org <github org>'")
+	}
+
+	meta, err := client.GetMeta(ctx)
+	if err == nil {
+		out.Version.API = meta.APIVersion
+		out.Version.MinCLI = meta.MinCLIVersion
+		out.Version.LatestCLI = meta.LatestCLIVersion
+	}
+
+	// an unknown minimum is not held against the CLI, the API rejects versions it doesn't support anyway
+	out.Version.Compatible = meta.MinCLIVersion == "" || compareVersions(out.Version.CLI, meta.MinCLIVersion) >= 0
+	if !out.Version.Compatible {
+		out.Problems = append(out.Problems, fmt.Sprintf("CLI %s is no longer supported, upgrade to %s or later at https://github.com/krmdv/cli/releases", out.Version.CLI, meta.MinCLIVersion))
+	}
+
+	if format.Structured() {
+		if err := output.Print(os.Stdout, format, out); err != nil {
+			return err
+		}
+	} else {
+		printStatus(out, err)
+	}
+
+	if n := len(out.Problems); n > 0 {
+		return fmt.Errorf("%d problem(s) found", n)
+	}
+
+	return nil
+}
+
+func printStatus(out statusOutput, metaErr error) {
+	ok := color.New(color.FgGreen).Sprint("✓")
+	ko := color.New(color.FgRed).Sprint("✗")
+	warn := color.New(color.FgYellow).Sprint("!")
+
+	fmt.Printf("Profile   %s (%s)\n", out.Profile, out.ConfigPath)
+	fmt.Printf("API host  %s\n\n", out.Host)
+
+	switch {
+	case out.Token.Source == "":
+		fmt.Printf("  %s Not logged in, run 'karma login'\n", ko)
+	case out.Token.Valid:
+		fmt.Printf("  %s Logged in as %s, with a token from %s\n", ok, out.User, out.Token.Source)
+	case out.tokenErrorKind == api.KindAuth:
+		fmt.Printf("  %s Token from %s not accepted: %s\n", ko, out.Token.Source, out.Token.Error)
+	default:
+		fmt.Printf("  %s Couldn't check the token from %s: %s\n", ko, out.Token.Source, out.Token.Error)
+	}
+
+	if out.Team.ID == "" {
+		fmt.Printf("  %s No team set up, run 'karma config --org <github org>'\n", ko)
+	} else {
+		fmt.Printf("  %s Team %s (%s)\n", ok, out.Team.Name, out.Team.ID)
+	}
+
+	switch {
+	case out.Cache.SyncedAt == nil:
+		fmt.Printf("  %s %d users and %d feats cached\n", warn, out.Cache.Users, out.Cache.Feats)
+	case out.Cache.Stale:
+		fmt.Printf("  %s %d users and %d feats cached %s ago, run 'karma sync' to refresh them\n", warn, out.Cache.Users, out.Cache.Feats, age(time.Since(*out.Cache.SyncedAt)))
+	default:
+		fmt.Printf("  %s %d users and %d feats cached %s ago\n", ok, out.Cache.Users, out.Cache.Feats, age(time.Since(*out.Cache.SyncedAt)))
+	}
+
+	switch {
+	case !out.Version.Compatible:
+		fmt.Printf("  %s CLI %s is no longer supported by the API, upgrade to %s or later\n", ko, out.Version.CLI, out.Version.MinCLI)
+	case metaErr != nil:
+		fmt.Printf("  %s CLI %s, couldn't check which versions the API supports: %v\n", warn, out.Version.CLI, metaErr)
+	case out.Version.LatestCLI != "" && compareVersions(out.Version.CLI, out.Version.LatestCLI) < 0:
+		fmt.Printf("  %s CLI %s is supported by API %s, but %s is available\n", warn, out.Version.CLI, out.Version.API, out.Version.LatestCLI)
+	default:
+		fmt.Printf("  %s CLI %s is supported by API %s\n", ok, out.Version.CLI, out.Version.API)
+	}
+}
+
+func age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "less than a minute"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// compareVersions compares versions like v1.2.3, returning -1, 0 or 1
+func compareVersions(a string, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(p)
+		parts = append(parts, n)
+	}
+
+	return parts
+}
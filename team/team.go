@@ -0,0 +1,418 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	cheerCmd "github.com/krmdv/cli/cheer"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// memberOutput is the structured description of a team member
+type memberOutput struct {
+	api.User
+	Me bool `json:"me"`
+}
+
+// removeOutput is the structured result of removing a member
+type removeOutput struct {
+	Removed api.User `json:"removed"`
+	Team    string   `json:"team"`
+}
+
+var roles = []string{api.RoleAdmin, api.RoleMember}
+
+// NewCmdTeam creates a team command
+func NewCmdTeam(client *api.Client, conf *config.Configuration) *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "team",
+		Short: "Manage the members of your team",
+		Long: heredoc.Doc(`
+			List the members of your team, invite developers and manage roles.
+
+			Members without a Karma account can be cheered, but only get their karma once
+			they join: invite them with 'karma team invite'. Admins can manage feats and
+			members, and only they can remove members or change roles.
+		`),
+		Example: heredoc.Doc(`
+			$ karma team
+			$ karma team invite cjones
+			$ karma team invite new.dev@example.com
+
+			# admins only
+			$ karma team role alice admin
+			$ karma team remove bobsmith
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMembers(cmd, client, conf)
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "members",
+		Short:   "List team members, their role and whether they have an account",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMembers(cmd, client, conf)
+		},
+	})
+
+	inviteCmd := &cobra.Command{
+		Use:   "invite [<developer> | <email>]",
+		Short: "Create a link to join your team",
+		Long: heredoc.Doc(`
+			Create a link to join your team with a Karma account, for a team member who
+			has none yet, an email, or anyone with the link if none is given.
+
+			Only admins can invite other admins.
+		`),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeDeveloper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			role, _ := cmd.Flags().GetString("role")
+
+			var who string
+			if len(args) > 0 {
+				who = args[0]
+			}
+
+			return inviteRun(cmd.Context(), *client, *conf, format, who, role)
+		},
+	}
+	inviteCmd.Flags().String("role", api.RoleMember, "role of the invited developer: admin or member")
+	inviteCmd.RegisterFlagCompletionFunc("role", completeRoles)
+	cmd.AddCommand(inviteCmd)
+
+	removeCmd := &cobra.Command{
+		Use:               "remove <developer>",
+		Short:             "Remove a member from your team, admins only",
+		Aliases:           []string{"rm"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDeveloper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			yes, _ := cmd.Flags().GetBool("yes")
+
+			return output.HandleInterrupt(removeRun(cmd.Context(), *client, *conf, format, args[0], yes))
+		},
+	}
+	removeCmd.Flags().BoolP("yes", "y", false, "remove without asking for confirmation")
+	cmd.AddCommand(removeCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "role <developer> <admin|member>",
+		Short: "Change the role of a team member, admins only",
+		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return cheerCmd.CompleteDevelopers(cmd, args, toComplete)
+			case 1:
+				return completeRoles(cmd, args, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return roleRun(cmd.Context(), *client, *conf, format, args[0], args[1])
+		},
+	})
+
+	return cmd
+}
+
+func runMembers(cmd *cobra.Command, client *api.Client, conf *config.Configuration) error {
+	if err := config.CheckLoaded(); err != nil {
+		return err
+	}
+
+	format, err := output.FromFlags(cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	return membersRun(cmd.Context(), *client, *conf, format)
+}
+
+func membersRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format) error {
+	team, err := client.GetTeam(ctx)
+	if err != nil {
+		return err
+	}
+
+	config.SaveUsers(team.Users)
+
+	members := []memberOutput{}
+	for _, u := range team.Users {
+		members = append(members, memberOutput{User: u, Me: u.ID == conf.User.ID})
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, members)
+	}
+
+	inactive := 0
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tLOGIN\tEMAIL\tROLE\tACCOUNT")
+	for _, m := range members {
+		marker := ""
+		if m.Me {
+			marker = "*"
+		}
+
+		account := "active"
+		if !m.Active {
+			account = "none yet"
+			inactive++
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", marker, m.Name, m.Login, m.Email, m.Role, account)
+	}
+	w.Flush()
+
+	if inactive > 0 {
+		color.Yellow(fmt.Sprintf("\n%d of %d members have no Karma account yet, cheers only reach them once they join. Invite them with 'karma team invite <developer>'.", inactive, len(members)))
+	}
+
+	return nil
+}
+
+func inviteRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format, who string, role string) error {
+	if err := validateRole(role); err != nil {
+		return err
+	}
+
+	var email, name string
+	switch {
+	case strings.Contains(who, "@"):
+		email, name = who, who
+	case who != "":
+		user, err := findMember(ctx, client, who)
+		if err != nil {
+			return err
+		}
+
+		if user.Active {
+			return fmt.Errorf("%s already has a Karma account", user.Name)
+		}
+
+		email, name = user.Email, user.Name
+	}
+
+	invite, err := client.CreateInvite(ctx, email, role)
+	if err != nil {
+		return err
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, invite)
+	}
+
+	expires := invite.ExpiresAt.Local().Format("Jan 2")
+	if name != "" {
+		color.Green(fmt.Sprintf("💌 Send this link to %s to join %s as %s, before %s:", name, conf.Team.Name, withArticle(invite.Role), expires))
+	} else {
+		color.Green(fmt.Sprintf("💌 Anyone with this link can join %s as %s, before %s:", conf.Team.Name, withArticle(invite.Role), expires))
+	}
+
+	fmt.Println(invite.URL)
+
+	return nil
+}
+
+func removeRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format, who string, yes bool) error {
+	user, err := findMember(ctx, client, who)
+	if err != nil {
+		return err
+	}
+
+	if !yes {
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("pass --yes to remove %s without a prompt", user.Name)
+		}
+
+		message := fmt.Sprintf("Remove %s from %s?", user.Name, conf.Team.Name)
+		if user.ID == conf.User.ID {
+			message = fmt.Sprintf("Remove yourself from %s? You won't be able to cheer its members anymore.", conf.Team.Name)
+		}
+
+		err := survey.AskOne(&survey.Confirm{Message: message}, &yes)
+		if err != nil || !yes {
+			return err
+		}
+	}
+
+	if err := client.RemoveMember(ctx, user.ID); err != nil {
+		return err
+	}
+
+	var users []api.User
+	for _, u := range conf.Users {
+		if u.ID != user.ID {
+			users = append(users, u)
+		}
+	}
+
+	if err := config.SaveUsers(users); err != nil {
+		return err
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, removeOutput{Removed: user, Team: conf.Team.Name})
+	}
+
+	color.Green(fmt.Sprintf("✅ Removed %s from %s.", user.Name, conf.Team.Name))
+
+	return nil
+}
+
+func roleRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format, who string, role string) error {
+	if err := validateRole(role); err != nil {
+		return err
+	}
+
+	user, err := findMember(ctx, client, who)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == role {
+		if format.Structured() {
+			return output.Print(os.Stdout, format, memberOutput{User: user, Me: user.ID == conf.User.ID})
+		}
+
+		color.Yellow(fmt.Sprintf("%s already %s of %s.", subject(user, conf), withArticle(role), conf.Team.Name))
+		return nil
+	}
+
+	updated, err := client.SetMemberRole(ctx, user.ID, role)
+	if err != nil {
+		return err
+	}
+
+	users := append([]api.User{}, conf.Users...)
+	for i, u := range users {
+		if u.ID == updated.ID {
+			users[i] = updated
+		}
+	}
+
+	if err := config.SaveUsers(users); err != nil {
+		return err
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, memberOutput{User: updated, Me: updated.ID == conf.User.ID})
+	}
+
+	color.Green(fmt.Sprintf("✅ %s now %s of %s.", subject(updated, conf), withArticle(updated.Role), conf.Team.Name))
+
+	return nil
+}
+
+// findMember designates a team member like cheers do, checking the API as cached members may be
+// out of date
+func findMember(ctx context.Context, client api.Client, who string) (api.User, error) {
+	team, err := client.GetTeam(ctx)
+	if err != nil {
+		return api.User{}, err
+	}
+
+	return cheerCmd.MatchUser(team.Users, who)
+}
+
+// subject is how a member is called in a sentence about their role, e.g. "Alice Martin is"
+func subject(user api.User, conf config.Configuration) string {
+	if user.ID == conf.User.ID {
+		return "You are"
+	}
+
+	return user.Name + " is"
+}
+
+func withArticle(role string) string {
+	if role == api.RoleAdmin {
+		return "an admin"
+	}
+
+	return "a " + role
+}
+
+func validateRole(role string) error {
+	if role != api.RoleAdmin && role != api.RoleMember {
+		return fmt.Errorf("unknown role %q, use %s", role, strings.Join(roles, " or "))
+	}
+
+	return nil
+}
+
+// completeDeveloper suggests team members for the first argument only
+func completeDeveloper(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cheerCmd.CompleteDevelopers(cmd, args, toComplete)
+}
+
+func completeRoles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return roles, cobra.ShellCompDirectiveNoFileComp
+}
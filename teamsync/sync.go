@@ -0,0 +1,137 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package teamsync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/fatih/color"
+	"github.com/krmdv/cli/api"
+	"github.com/krmdv/cli/config"
+	"github.com/krmdv/cli/output"
+	"github.com/spf13/cobra"
+)
+
+// syncOutput is the structured result of a sync
+type syncOutput struct {
+	Team     string     `json:"team"`
+	Users    []api.User `json:"users"`
+	Feats    []api.Feat `json:"feats"`
+	SyncedAt time.Time  `json:"syncedAt"`
+}
+
+// NewCmdSync creates a sync command
+func NewCmdSync(client *api.Client, conf *config.Configuration) *cobra.Command {
+
+	var cmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Refresh cached team members and feats",
+		Long: heredoc.Doc(`
+			Refresh the team members and feats cached in your profile.
+
+			They are refreshed in the background when older than an hour, or the
+			duration set with KARMA_CACHE_TTL, and when cheering someone who isn't
+			cached yet. Run this command to get them right away.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.CheckLoaded(); err != nil {
+				return err
+			}
+
+			format, err := output.FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			return syncRun(cmd.Context(), *client, *conf, format)
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func syncRun(ctx context.Context, client api.Client, conf config.Configuration, format output.Format) error {
+	cache, err := config.FetchTeamCache(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if err := config.SaveTeamCache(cache); err != nil {
+		return err
+	}
+
+	if format.Structured() {
+		return output.Print(os.Stdout, format, syncOutput{
+			Team:     cache.Team.Name,
+			Users:    cache.Team.Users,
+			Feats:    cache.Feats,
+			SyncedAt: config.SyncedAt(),
+		})
+	}
+
+	for _, u := range cache.Team.Users {
+		if !hasUser(conf.Users, u.ID) {
+			fmt.Printf("+ %s joined the team\n", u.Name)
+		}
+	}
+	for _, u := range conf.Users {
+		if !hasUser(cache.Team.Users, u.ID) {
+			fmt.Printf("- %s left the team\n", u.Name)
+		}
+	}
+	for _, f := range cache.Feats {
+		if !hasFeat(conf.Feats, f.ID) {
+			fmt.Printf("+ new feat %s (%s, %d pts)\n", f.Label, f.Slug, f.Karma)
+		}
+	}
+	for _, f := range conf.Feats {
+		if !hasFeat(cache.Feats, f.ID) {
+			fmt.Printf("- feat %s is gone\n", f.Label)
+		}
+	}
+
+	color.Green(fmt.Sprintf("✅ Synced %d members and %d feats of %s.", len(cache.Team.Users), len(cache.Feats), cache.Team.Name))
+
+	return nil
+}
+
+func hasUser(users []api.User, id string) bool {
+	for _, u := range users {
+		if u.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasFeat(feats []api.Feat, id string) bool {
+	for _, f := range feats {
+		if f.ID == id {
+			return true
+		}
+	}
+
+	return false
+}